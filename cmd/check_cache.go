@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"quiz/config"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"go.uber.org/zap"
+)
+
+// runCheckCache implements the check-cache command, which compares the cached leaderboard of a board with the scores
+// in the database, prints the report as JSON, and repairs the drift when asked to. Without --repair the command exits
+// with status 2 when drift was found, so it can be used by monitoring. Only the mongo_redis storage backend keeps a
+// cache that outlives the process, so the command refuses to run with any other backend.
+func runCheckCache(ctx context.Context, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("check-cache", flag.ExitOnError)
+	boardID := flags.String("board", "", "ID of the board whose cached leaderboard is checked")
+	repair := flags.Bool("repair", false, "copy every drifted player from the database to the cache")
+	flags.Parse(args)
+
+	if *boardID == "" {
+		log.Fatalf("check-cache needs a board, pass it with --board")
+	}
+	if cfg.StorageBackend != config.StorageMongoRedis {
+		log.Fatalf("check-cache needs the %q storage backend, got %q", config.StorageMongoRedis, cfg.StorageBackend)
+	}
+
+	mongoClient := repositories.NewMongoDBClient(ctx, cfg.MongoDBURI)
+	mongoClient.Connect()
+	defer mongoClient.Close()
+
+	redisClient := repositories.NewRedisClient(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDBIndex, cacheExpiry(cfg))
+	redisClient.Connect()
+	defer redisClient.Close()
+
+	b, err := mongoClient.GetBoard(*boardID)
+	if err != nil {
+		log.Fatalf("Failed to read board %q: %v", *boardID, err)
+	}
+
+	report, err := service.NewConsistencyService(mongoClient, redisClient, ctx, zap.NewNop()).CheckBoard(b, *repair)
+	if err != nil {
+		log.Fatalf("Failed to check the cache: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if !report.Consistent() && !*repair {
+		// The deferred Close calls do not run on os.Exit, close the connections first
+		redisClient.Close()
+		mongoClient.Close()
+		os.Exit(2)
+	}
+}
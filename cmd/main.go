@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"log"
+	"os"
+	"quiz/internals/domain/window"
 	"quiz/internals/repositories"
 	"quiz/internals/service"
 	"quiz/internals/transport/http"
@@ -19,15 +21,67 @@ func main() {
 	// Load application configuration from environment variables or .env file
 	cfg := config.LoadConfig()
 
-	// Connect to MongoDB using the URI from the configuration
-	mongoClient := repositories.NewMongoDBClient(ctx, cfg.MongoDBURI)
-	mongoClient.Connect()     // Establish the MongoDB connection
-	defer mongoClient.Close() // Ensure the connection is closed on exit
+	// Run a maintenance command instead of the server when one is named on the command line
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate-cache":
+			runMigrateCache(ctx, cfg, os.Args[2:])
+			return
+		case "migrate-scores":
+			runMigrateScores(ctx, cfg, os.Args[2:])
+			return
+		case "check-cache":
+			runCheckCache(ctx, cfg, os.Args[2:])
+			return
+		case "rebuild-cache":
+			runRebuildCache(ctx, cfg, os.Args[2:])
+			return
+		}
+	}
+
+	// Pick the database and cache implementations based on the configured storage backend
+	var dbClient repositories.IDBRepository
+	var boardClient repositories.IBoardRepository
+	var historyClient repositories.IHistoryRepository
+	var groupClient repositories.IGroupRepository
+	var profileClient repositories.IProfileRepository
+	var socialClient repositories.ISocialRepository
+	var seasonClient repositories.ISeasonRepository
+	var snapshotClient repositories.ISnapshotRepository
+	var standingsClient repositories.IStandingsRepository
+	var outboxClient repositories.IOutboxRepository
+	var scoreStreamClient repositories.IScoreStreamRepository // Only available with MongoDB
+	var cacheClient repositories.ICacheRepository
+	switch cfg.StorageBackend {
+	case config.StorageMemory:
+		memoryClient := repositories.NewMemoryDBClient()
+		dbClient, boardClient, historyClient = memoryClient, memoryClient, memoryClient
+		groupClient, seasonClient, snapshotClient, standingsClient = memoryClient, memoryClient, memoryClient, memoryClient
+		profileClient, socialClient, outboxClient = memoryClient, memoryClient, memoryClient
+		cacheClient = repositories.NewMemoryCacheClient()
+	case config.StorageMongoRedis:
+		// MongoDB using the URI, and Redis using the address, password, and database index from the configuration
+		mongoClient := repositories.NewMongoDBClient(ctx, cfg.MongoDBURI)
+		dbClient, boardClient, historyClient = mongoClient, mongoClient, mongoClient
+		groupClient, seasonClient, snapshotClient, standingsClient = mongoClient, mongoClient, mongoClient, mongoClient
+		profileClient, socialClient, outboxClient = mongoClient, mongoClient, mongoClient
+		scoreStreamClient = mongoClient
+		cacheClient = repositories.NewRedisClient(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDBIndex, cacheExpiry(cfg))
+	default:
+		log.Fatalf("Unknown storage backend: %q", cfg.StorageBackend)
+	}
+
+	dbClient.Connect()     // Establish the database connection
+	defer dbClient.Close() // Ensure the connection is closed on exit
 
-	// Connect to Redis using the address, password, and database index from the configuration
-	redisClient := repositories.NewRedisClient(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDBIndex)
-	redisClient.Connect()     // Establish the Redis connection
-	defer redisClient.Close() // Ensure the connection is closed on exit
+	cacheClient.Connect()     // Establish the cache connection
+	defer cacheClient.Close() // Ensure the connection is closed on exit
+
+	// Parse the leaderboard windows kept next to the all-time leaderboard of every board
+	windows, err := window.ParseSchedule(cfg.Windows, cfg.Timezone, cfg.WindowRetention)
+	if err != nil {
+		log.Fatalf("Invalid leaderboard windows: %v", err)
+	}
 
 	// Create a production logger using Uber's Zap library
 	logger, err := zap.NewProduction()
@@ -38,35 +92,215 @@ func main() {
 
 	// Log an informational message indicating the application is starting
 	logger.Info("Application starting",
-		zap.String("mongo_uri", cfg.MongoDBURI), // Log MongoDB URI
-		zap.String("redis_addr", cfg.RedisAddr), // Log Redis address
+		zap.String("storage_backend", cfg.StorageBackend),      // Log storage backend
+		zap.String("mongo_uri", cfg.MongoDBURI),                // Log MongoDB URI
+		zap.String("redis_addr", cfg.RedisAddr),                // Log Redis address
+		zap.String("windows", cfg.Windows),                     // Log leaderboard windows
+		zap.String("timezone", cfg.Timezone),                   // Log leaderboard timezone
+		zap.Bool("score_stream", cfg.ScoreStream),              // Log whether the score stream worker runs
+		zap.Bool("cache_warmup", cfg.CacheWarmup),              // Log whether the cache is rebuilt before serving
+		zap.Duration("leaderboard_ttl", cfg.LeaderboardTTL),    // Log the sliding expiry of the cached leaderboards
+		zap.Duration("player_ttl", cfg.PlayerTTL),              // Log the sliding expiry of the cached players
+		zap.Duration("rebuild_wait", cfg.RebuildWait),          // Log the longest wait of a read for a leaderboard rebuild
+		zap.Int("cache_memory_limit_mb", cfg.CacheMemoryLimit), // Log the memory limit enforced by evicting cold boards
 	)
 
+	// Setup the Group service with dependencies
+	groupService := service.NewGroupService(groupClient, dbClient, cacheClient, ctx, logger)
+
+	// Setup the Outbox relay applying the score changes to the cache, and start it
+	outboxRelay := service.NewOutboxRelay(outboxClient, dbClient, boardClient, cacheClient, ctx, logger)
+	outboxRelay.Start()
+
+	// Setup the Score stream worker applying writes of other applications to the cache, if enabled
+	if cfg.ScoreStream {
+		if scoreStreamClient == nil {
+			log.Fatalf("The score stream worker needs the %q storage backend", config.StorageMongoRedis)
+		}
+		service.NewScoreStreamWorker(scoreStreamClient, boardClient, cacheClient, outboxClient, ctx, logger).Start()
+	}
+
+	// Setup the Consistency service comparing the cache with the database
+	consistencyService := service.NewConsistencyService(dbClient, cacheClient, ctx, logger)
+
+	// Setup the Cache evictor removing the least recently accessed boards from the cache, if a memory limit is set
+	if cfg.CacheMemoryLimit > 0 {
+		service.NewCacheEvictor(cacheClient, int64(cfg.CacheMemoryLimit)<<20, ctx, logger).Start()
+	}
+
 	// Setup the Player Score service with dependencies
 	playerScoresService := service.NewPlayerScoreService(
-		mongoClient, // MongoDB client
-		redisClient, // Redis client
-		ctx,         // Context for cancellation and deadlines
-		logger,      // Logger for the service
+		dbClient,        // Database client
+		cacheClient,     // Cache client
+		historyClient,   // Score history client
+		windows,         // Windowed leaderboards
+		groupService,    // Group leaderboards
+		profileClient,   // Player profiles joined into leaderboards
+		outboxRelay,     // Relay applying score changes to the cache
+		cfg.RebuildWait, // Longest wait of a read for a cold leaderboard rebuild
+		ctx,             // Context for cancellation and deadlines
+		logger,          // Logger for the service
 	)
 
-	// Setup the HTTP handlers for player scores
+	// Rebuild the cached leaderboards before serving requests, if enabled. A failed warmup is not fatal, cold
+	// leaderboards are rebuilt on their first read
+	if cfg.CacheWarmup {
+		if boards, err := boardClient.GetBoards(); err != nil {
+			logger.Error("Failed to read the boards to warm up", zap.Error(err))
+		} else if players, err := rebuildBoards(playerScoresService, boards, logger); err != nil {
+			logger.Error("Failed to warm up the cache", zap.Error(err))
+		} else {
+			logger.Info("Cache warmed up", zap.Int("boards", len(boards)), zap.Int("players", players))
+		}
+	}
+
+	// Setup the Board service with dependencies
+	boardService := service.NewBoardService(boardClient, cacheClient, ctx, logger)
+
+	// Setup the Profile service with dependencies
+	profileService := service.NewProfileService(profileClient, outboxRelay, ctx, logger)
+
+	// Setup the Social service with dependencies
+	socialService := service.NewSocialService(socialClient, playerScoresService, ctx, logger)
+
+	// Setup the Season service with dependencies
+	seasonService := service.NewSeasonService(seasonClient, standingsClient, playerScoresService, ctx, logger)
+
+	// Setup the Snapshot service with dependencies
+	snapshotService := service.NewSnapshotService(snapshotClient, standingsClient, playerScoresService, ctx, logger)
+
+	// Setup the HTTP handlers for player scores and boards
 	playerScoresHandler := http.NewPlayerScoreHandler(playerScoresService)
+	boardsHandler := http.NewBoardsHandler(boardService)
+	seasonsHandler := http.NewSeasonsHandler(seasonService)
+	snapshotsHandler := http.NewSnapshotsHandler(snapshotService)
+	groupsHandler := http.NewGroupsHandler(groupService)
+	profilesHandler := http.NewProfilesHandler(profileService)
+	socialHandler := http.NewSocialHandler(socialService)
+	outboxHandler := http.NewOutboxHandler(outboxRelay)
+	consistencyHandler := http.NewConsistencyHandler(consistencyService)
 
-	// Initialize the Gin router and setup routes grouped under the /points subroute
+	// Initialize the Gin router and setup routes grouped under the /boards subroute
 	router := gin.Default()
-	v1 := router.Group("/points")
+	router.Use(http.RequestID) // Assign an ID to every request, recorded in the score history
+	boards := router.Group("/boards")
+	{
+		// Routes to create, list, get and delete boards
+		boards.POST("", boardsHandler.CreateBoardHandler)
+		boards.GET("", boardsHandler.ListBoardsHandler)
+		boards.GET("/:board", boardsHandler.GetBoardHandler)
+		boards.DELETE("/:board", boardsHandler.DeleteBoardHandler)
+	}
+
+	// Score routes are grouped under the /points subroute of an existing board
+	v1 := boards.Group("/:board/points", boardsHandler.RequireBoard)
 	{
 		// Route to add or update player scores
 		v1.POST("/add_or_update", playerScoresHandler.AddOrUpdateHandler)
+		v1.POST("/increment", playerScoresHandler.IncrementHandler)
 
 		// Route to get the top players' scores
 		v1.GET("/top_players", playerScoresHandler.TopPlayersHandler)
 
 		// Route to get points for a specific player by ID
 		v1.GET("/get_points/:id", playerScoresHandler.GetPointsHandler)
+
+		// Route to get the rank of a specific player by ID
+		v1.GET("/rank/:id", playerScoresHandler.GetRankHandler)
+
+		// Route to get the players ranked around a specific player by ID
+		v1.GET("/around/:id", playerScoresHandler.AroundHandler)
+
+		// Route to get the score history of a specific player by ID
+		v1.GET("/history/:id", playerScoresHandler.HistoryHandler)
+
+		// Route to rank a specific player among their friends by ID
+		v1.GET("/friends/:id", socialHandler.FriendsLeaderboardHandler)
+
+		// Route to get the groups ranked by the scores of their members
+		v1.GET("/top_groups", groupsHandler.TopGroupsHandler)
+	}
+
+	// Season routes are grouped under the /seasons subroute of an existing board
+	seasons := boards.Group("/:board/seasons", boardsHandler.RequireBoard)
+	{
+		// Routes to start, list and get seasons
+		seasons.POST("", seasonsHandler.StartSeasonHandler)
+		seasons.GET("", seasonsHandler.ListSeasonsHandler)
+		seasons.GET("/:season", seasonsHandler.GetSeasonHandler)
+
+		// Route to end a season, archiving its standings and resetting the board
+		seasons.POST("/:season/end", seasonsHandler.EndSeasonHandler)
+
+		// Route to get the final standings of a season
+		seasons.GET("/:season/top_players", seasonsHandler.SeasonTopPlayersHandler)
+	}
+
+	// Snapshot routes are grouped under the /snapshots subroute of an existing board
+	snapshots := boards.Group("/:board/snapshots", boardsHandler.RequireBoard)
+	{
+		// Routes to take, list, get and delete snapshots
+		snapshots.POST("", snapshotsHandler.TakeSnapshotHandler)
+		snapshots.GET("", snapshotsHandler.ListSnapshotsHandler)
+		snapshots.GET("/:snapshot", snapshotsHandler.GetSnapshotHandler)
+		snapshots.DELETE("/:snapshot", snapshotsHandler.DeleteSnapshotHandler)
+
+		// Route to get the ranked players of a snapshot
+		snapshots.GET("/:snapshot/top_players", snapshotsHandler.SnapshotTopPlayersHandler)
+
+		// Route to compare a snapshot with an earlier one
+		snapshots.GET("/:snapshot/diff", snapshotsHandler.DiffSnapshotsHandler)
+	}
+
+	// Group routes are grouped under the /groups subroute, groups are shared by every board
+	groups := router.Group("/groups")
+	{
+		// Routes to create, list, get and delete groups
+		groups.POST("", groupsHandler.CreateGroupHandler)
+		groups.GET("", groupsHandler.ListGroupsHandler)
+		groups.GET("/:group", groupsHandler.GetGroupHandler)
+		groups.DELETE("/:group", groupsHandler.DeleteGroupHandler)
+
+		// Routes to list, add and remove group members
+		groups.GET("/:group/members", groupsHandler.ListMembersHandler)
+		groups.PUT("/:group/members/:id", groupsHandler.AddMemberHandler)
+		groups.DELETE("/:group/members/:id", groupsHandler.RemoveMemberHandler)
+	}
+
+	// Player profile routes are grouped under the /players subroute, profiles are shared by every board
+	players := router.Group("/players")
+	{
+		// Routes to create, get, update and delete player profiles
+		players.POST("", profilesHandler.CreateProfileHandler)
+		players.GET("/:id", profilesHandler.GetProfileHandler)
+		players.PATCH("/:id", profilesHandler.UpdateProfileHandler)
+		players.DELETE("/:id", profilesHandler.DeleteProfileHandler)
+
+		// Routes to follow and unfollow players and to list the social graph of a player
+		players.PUT("/:id/following/:other", socialHandler.FollowHandler)
+		players.DELETE("/:id/following/:other", socialHandler.UnfollowHandler)
+		players.GET("/:id/following", socialHandler.FollowingHandler)
+		players.GET("/:id/followers", socialHandler.FollowersHandler)
+		players.GET("/:id/friends", socialHandler.FriendsHandler)
+	}
+
+	// Administrative routes are grouped under the /admin subroute
+	admin := router.Group("/admin")
+	{
+		// Routes to inspect and retry the outbox entries the relay gave up on
+		admin.GET("/outbox/dead", outboxHandler.DeadEntriesHandler)
+		admin.POST("/outbox/dead/:id/retry", outboxHandler.RetryDeadEntryHandler)
+
+		// Routes to check the cached leaderboard of a board against the database and to repair it
+		admin.GET("/boards/:board/consistency", boardsHandler.RequireBoard, consistencyHandler.CheckHandler)
+		admin.POST("/boards/:board/consistency/repair", boardsHandler.RequireBoard, consistencyHandler.RepairHandler)
 	}
 
 	// Start the HTTP server on port 8000
 	router.Run(":8000")
 }
+
+// cacheExpiry returns the sliding expiry of the cached leaderboards and player HASHes configured by cfg.
+func cacheExpiry(cfg *config.Config) repositories.CacheExpiry {
+	return repositories.CacheExpiry{LeaderboardTTL: cfg.LeaderboardTTL, PlayerTTL: cfg.PlayerTTL}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"quiz/config"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"time"
+)
+
+// runMigrateCache implements the migrate-cache command, which rewrites the keys of a Redis cache written by an
+// earlier version of the service into the current key schema. Only the mongo_redis storage backend keeps a cache
+// that outlives the process, so the command refuses to run with any other backend.
+func runMigrateCache(ctx context.Context, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("migrate-cache", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "count the keys that would be migrated without rewriting them")
+	flags.Parse(args)
+
+	if cfg.StorageBackend != config.StorageMongoRedis {
+		log.Fatalf("migrate-cache needs the %q storage backend, got %q", config.StorageMongoRedis, cfg.StorageBackend)
+	}
+
+	redisClient := repositories.NewRedisClient(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDBIndex, cacheExpiry(cfg))
+	redisClient.Connect()
+	defer redisClient.Close()
+
+	report, err := redisClient.MigrateKeySchema(*dryRun)
+	if err != nil {
+		log.Fatalf("Failed to migrate the cache: %v", err)
+	}
+
+	log.Printf("Cache migrated from schema version %d to %d (dry run: %t): %d leaderboards, %d group leaderboards, %d players, %d keys skipped",
+		report.PreviousVersion, report.MigratedToVersion, report.DryRun, report.Leaderboards, report.GroupLeaderboards, report.Players, report.Skipped)
+}
+
+// runMigrateScores implements the migrate-scores command, which assigns the player scores written before boards were
+// introduced to the default board, creating the board with the default policies when it does not exist yet. Only the
+// mongo_redis storage backend keeps scores that outlive the process, so the command refuses to run with any other
+// backend.
+func runMigrateScores(ctx context.Context, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("migrate-scores", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "count the scores that would be migrated without rewriting them")
+	flags.Parse(args)
+
+	if cfg.StorageBackend != config.StorageMongoRedis {
+		log.Fatalf("migrate-scores needs the %q storage backend, got %q", config.StorageMongoRedis, cfg.StorageBackend)
+	}
+
+	mongoClient := repositories.NewMongoDBClient(ctx, cfg.MongoDBURI)
+	mongoClient.Connect()
+	defer mongoClient.Close()
+
+	if !*dryRun {
+		b := board.Board{
+			ID:           board.DefaultID,
+			Name:         board.DefaultID,
+			TieBreak:     player_score.DefaultTieBreak,
+			UpdatePolicy: player_score.DefaultUpdatePolicy,
+			CreatedAt:    time.Now().UTC(),
+		}
+		if err := mongoClient.CreateBoard(b); err != nil && !errors.Is(err, repositories.ErrBoardExists) {
+			log.Fatalf("Failed to create the %q board: %v", board.DefaultID, err)
+		}
+	}
+
+	report, err := mongoClient.MigrateLegacyScores(board.DefaultID, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to migrate the scores: %v", err)
+	}
+
+	log.Printf("Scores migrated to board %q (dry run: %t): %d moved, %d superseded by a score on the board",
+		report.BoardID, report.DryRun, report.Moved, report.Superseded)
+}
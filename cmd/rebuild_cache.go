@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"quiz/config"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"go.uber.org/zap"
+)
+
+// runRebuildCache implements the rebuild-cache command, which rebuilds the cached leaderboards of every board, or of
+// the board given with --board, from the database. Each leaderboard is swapped in as a whole, so the command may run
+// while the service is serving requests. Only the mongo_redis storage backend keeps a cache that outlives the process,
+// so the command refuses to run with any other backend.
+func runRebuildCache(ctx context.Context, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("rebuild-cache", flag.ExitOnError)
+	boardID := flags.String("board", "", "ID of the only board to rebuild, every board is rebuilt when empty")
+	flags.Parse(args)
+
+	if cfg.StorageBackend != config.StorageMongoRedis {
+		log.Fatalf("rebuild-cache needs the %q storage backend, got %q", config.StorageMongoRedis, cfg.StorageBackend)
+	}
+
+	windows, err := window.ParseSchedule(cfg.Windows, cfg.Timezone, cfg.WindowRetention)
+	if err != nil {
+		log.Fatalf("Invalid leaderboard windows: %v", err)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Sync()
+
+	mongoClient := repositories.NewMongoDBClient(ctx, cfg.MongoDBURI)
+	mongoClient.Connect()
+	defer mongoClient.Close()
+
+	redisClient := repositories.NewRedisClient(ctx, cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDBIndex, cacheExpiry(cfg))
+	redisClient.Connect()
+	defer redisClient.Close()
+
+	// The relay is only handed to the service, the command writes no scores for it to apply
+	groupService := service.NewGroupService(mongoClient, mongoClient, redisClient, ctx, logger)
+	outboxRelay := service.NewOutboxRelay(mongoClient, mongoClient, mongoClient, redisClient, ctx, logger)
+	playerScoresService := service.NewPlayerScoreService(mongoClient, redisClient, mongoClient, windows, groupService, mongoClient, outboxRelay, cfg.RebuildWait, ctx, logger)
+
+	var boards []board.Board
+	if *boardID != "" {
+		b, err := mongoClient.GetBoard(*boardID)
+		if err != nil {
+			log.Fatalf("Failed to read board %q: %v", *boardID, err)
+		}
+		boards = []board.Board{b}
+	} else if boards, err = mongoClient.GetBoards(); err != nil {
+		log.Fatalf("Failed to read the boards: %v", err)
+	}
+
+	players, err := rebuildBoards(playerScoresService, boards, logger)
+	if err != nil {
+		log.Fatalf("Failed to rebuild the cache: %v", err)
+	}
+	log.Printf("Cache rebuilt: %d boards, %d players", len(boards), players)
+}
+
+// rebuildBoards rebuilds the cached leaderboards of the boards one after another and returns the number of players
+// cached. Boards whose leaderboards another instance is rebuilding already are skipped, the first other failure stops
+// the rebuild.
+func rebuildBoards(playerScoresService *service.PlayerScoreService, boards []board.Board, logger *zap.Logger) (int, error) {
+	total := 0
+	for _, b := range boards {
+		players, err := playerScoresService.RebuildBoard(b)
+		total += players
+		if errors.Is(err, service.ErrRebuildInProgress) {
+			logger.Info("Skipped leaderboards rebuilt by another instance", zap.String("board_id", b.ID))
+			continue
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
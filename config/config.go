@@ -4,17 +4,34 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Supported values for the STORAGE_BACKEND setting.
+const (
+	StorageMongoRedis = "mongo_redis" // MongoDB as the database and Redis as the cache
+	StorageMemory     = "memory"      // In-memory database and cache, no external services required
+)
+
 // Config holds all the necessary configuration settings for the application,
 // including database URIs and Redis connection details.
 type Config struct {
-	MongoDBURI    string // MongoDB connection URI
-	RedisAddr     string // Redis server address
-	RedisPassword string // Redis password (if required)
-	RedisDBIndex  int    // Redis database index to use
+	StorageBackend   string        // Storage backend to use, either StorageMongoRedis or StorageMemory
+	MongoDBURI       string        // MongoDB connection URI
+	RedisAddr        string        // Redis server address
+	RedisPassword    string        // Redis password (if required)
+	RedisDBIndex     int           // Redis database index to use
+	Windows          string        // Comma separated leaderboard windows kept next to the all-time one (daily, weekly, monthly)
+	Timezone         string        // IANA timezone in which the leaderboard windows roll over
+	WindowRetention  time.Duration // Time the scores of a window period are kept in the database after the period ended
+	ScoreStream      bool          // Whether to apply every change of the MongoDB scores collection to Redis, including writes of other applications
+	CacheWarmup      bool          // Whether to rebuild the cached leaderboards of every board from MongoDB before serving requests
+	LeaderboardTTL   time.Duration // Time a cached leaderboard is kept after its last access, zero keeps it forever
+	RebuildWait      time.Duration // Longest time a read waits for the rebuild of a cold cached leaderboard before it is served from MongoDB
+	PlayerTTL        time.Duration // Time a cached player HASH is kept after its last access, zero keeps it forever
+	CacheMemoryLimit int           // Megabytes the cache may use before the least recently accessed boards are evicted, zero disables eviction
 }
 
 // LoadConfig reads the configuration from the .env file or environment variables.
@@ -26,10 +43,20 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		MongoDBURI:    getEnv("MONGODB_URI", "mongodb://localhost:27017"), // Default MongoDB URI
-		RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),             // Default Redis address
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),                       // Default Redis password (empty)
-		RedisDBIndex:  getEnvAsInt("REDIS_DB_INDEX", 0),                   // Default Redis DB index
+		StorageBackend:   getEnv("STORAGE_BACKEND", StorageMongoRedis),                   // Default to MongoDB and Redis
+		MongoDBURI:       getEnv("MONGODB_URI", "mongodb://localhost:27017"),             // Default MongoDB URI
+		RedisAddr:        getEnv("REDIS_ADDR", "localhost:6379"),                         // Default Redis address
+		RedisPassword:    getEnv("REDIS_PASSWORD", ""),                                   // Default Redis password (empty)
+		RedisDBIndex:     getEnvAsInt("REDIS_DB_INDEX", 0),                               // Default Redis DB index
+		Windows:          getEnv("LEADERBOARD_WINDOWS", "daily,weekly,monthly"),          // Default to every window
+		Timezone:         getEnv("LEADERBOARD_TIMEZONE", "UTC"),                          // Default to rolling over at midnight UTC
+		WindowRetention:  getEnvAsDuration("LEADERBOARD_WINDOW_RETENTION", 24*time.Hour), // Default to dropping a period a day after it ended
+		ScoreStream:      getEnvAsBool("SCORE_STREAM_WORKER", false),                     // Default to only caching the service's own writes
+		CacheWarmup:      getEnvAsBool("CACHE_WARMUP", false),                            // Default to rebuilding cold leaderboards on their first read
+		LeaderboardTTL:   getEnvAsDuration("CACHE_LEADERBOARD_TTL", 24*time.Hour),        // Default to dropping leaderboards unused for a day
+		PlayerTTL:        getEnvAsDuration("CACHE_PLAYER_TTL", 72*time.Hour),             // Default to outliving the leaderboards the players are shown on
+		RebuildWait:      getEnvAsDuration("CACHE_REBUILD_WAIT", 5*time.Second),          // Default to serving slow rebuilds from MongoDB after five seconds
+		CacheMemoryLimit: getEnvAsInt("CACHE_MEMORY_LIMIT_MB", 0),                        // Default to leaving memory pressure to the TTLs
 	}
 }
 
@@ -42,6 +69,30 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvAsBool retrieves the value of the environment variable identified by key
+// and converts it to a boolean. If the variable is not set or conversion fails,
+// it returns the provided fallback boolean value.
+func getEnvAsBool(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return fallback
+}
+
+// getEnvAsDuration retrieves the value of the environment variable identified by key
+// and parses it as a duration such as "30m" or "24h". If the variable is not set or parsing fails,
+// it returns the provided fallback duration.
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return fallback
+}
+
 // getEnvAsInt retrieves the value of the environment variable identified by key
 // and converts it to an integer. If the variable is not set or conversion fails,
 // it returns the provided fallback integer value.
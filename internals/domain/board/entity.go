@@ -0,0 +1,19 @@
+package board
+
+import (
+	"quiz/internals/domain/player_score"
+	"time"
+)
+
+// DefaultID is the ID of the board that scores written before boards were introduced belong to.
+const DefaultID = "default"
+
+// Board represents a single named leaderboard. Every player score belongs to exactly one board,
+// which allows several quizzes to run at the same time without sharing rankings.
+type Board struct {
+	ID           string                    `json:"id" bson:"_id"`                      // Unique identifier of the board, used in routes and cache keys
+	Name         string                    `json:"name" bson:"name"`                   // Human readable name of the board
+	TieBreak     player_score.TieBreak     `json:"tie_break" bson:"tie_break"`         // Policy ordering and ranking players with equal scores
+	UpdatePolicy player_score.UpdatePolicy `json:"update_policy" bson:"update_policy"` // Policy deciding whether a submitted score replaces the stored one
+	CreatedAt    time.Time                 `json:"created_at" bson:"created_at"`       // Time the board was created
+}
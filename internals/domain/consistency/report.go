@@ -0,0 +1,55 @@
+package consistency
+
+import (
+	"quiz/internals/domain/player_score"
+	"time"
+)
+
+// Kind classifies how a cached leaderboard entry differs from the database.
+type Kind string
+
+const (
+	KindMissing    Kind = "missing"    // The player has a score in the database but no entry in the cached leaderboard
+	KindExtra      Kind = "extra"      // The cached leaderboard has an entry for a player without a score in the database
+	KindMismatched Kind = "mismatched" // The cached entry ranks the player differently than the score in the database
+)
+
+// Drift is a player whose cached leaderboard entry differs from the database.
+type Drift struct {
+	PlayerID string                    `json:"player_id"`       // Player whose entries differ
+	Kind     Kind                      `json:"kind"`            // How the entries differ
+	DB       *player_score.PlayerScore `json:"db,omitempty"`    // Score in the database, nil for extra entries
+	Cache    *player_score.PlayerScore `json:"cache,omitempty"` // Entry in the cache, nil for missing entries
+}
+
+// Report is the result of comparing the cached leaderboard of a board with the scores in the database.
+type Report struct {
+	BoardID    string    `json:"board_id"`    // Board that was checked
+	DBCount    int       `json:"db_count"`    // Number of scores read from the database
+	CacheCount int       `json:"cache_count"` // Number of entries read from the cached leaderboard
+	Cold       bool      `json:"cold"`        // Whether the cached leaderboard was empty, it is rebuilt on its next read and not compared
+	Missing    int       `json:"missing"`     // Number of players missing from the cache
+	Extra      int       `json:"extra"`       // Number of cached entries without a score in the database
+	Mismatched int       `json:"mismatched"`  // Number of cached entries differing from the database
+	Drifts     []Drift   `json:"drifts"`      // Every player whose entries differ
+	Repaired   int       `json:"repaired"`    // Number of drifted entries repaired, zero unless a repair was requested
+	CheckedAt  time.Time `json:"checked_at"`  // Time the check started
+}
+
+// Add records a drifted player in the report.
+func (r *Report) Add(d Drift) {
+	switch d.Kind {
+	case KindMissing:
+		r.Missing++
+	case KindExtra:
+		r.Extra++
+	case KindMismatched:
+		r.Mismatched++
+	}
+	r.Drifts = append(r.Drifts, d)
+}
+
+// Consistent reports whether the cache matched the database.
+func (r Report) Consistent() bool {
+	return len(r.Drifts) == 0
+}
@@ -0,0 +1,69 @@
+package group
+
+import (
+	"sort"
+	"time"
+)
+
+// Aggregation is the way the scores of a group's members on a board are combined into the group's score.
+type Aggregation string
+
+const (
+	AggregateSum  Aggregation = "sum"   // Sum of the scores of every member
+	AggregateAvg  Aggregation = "avg"   // Average score of the members that have a score on the board
+	AggregateTopK Aggregation = "top_k" // Sum of the K best scores of the members
+
+	DefaultAggregation = AggregateSum // Aggregation used by groups created without one
+)
+
+// Valid reports whether the aggregation is one of the supported aggregations.
+func (a Aggregation) Valid() bool {
+	switch a {
+	case AggregateSum, AggregateAvg, AggregateTopK:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the aggregation, or DefaultAggregation when it is not set.
+func (a Aggregation) OrDefault() Aggregation {
+	if a == "" {
+		return DefaultAggregation
+	}
+	return a
+}
+
+// Group is a team, school or any other set of players ranked together on every board.
+type Group struct {
+	ID          string      `json:"id" bson:"_id"`                  // Unique identifier of the group, used in routes and cache members
+	Name        string      `json:"name" bson:"name"`               // Human readable name of the group
+	Aggregation Aggregation `json:"aggregation" bson:"aggregation"` // How member scores are combined into the group's score
+	K           int         `json:"k,omitempty" bson:"k"`           // Number of best member scores summed by AggregateTopK
+	CreatedAt   time.Time   `json:"created_at" bson:"created_at"`   // Time the group was created
+}
+
+// Aggregate combines the scores of the group's members on a board into the group's score.
+func (g Group) Aggregate(scores []int) float64 {
+	if g.Aggregation.OrDefault() == AggregateTopK && len(scores) > g.K {
+		best := append([]int(nil), scores...)
+		sort.Sort(sort.Reverse(sort.IntSlice(best)))
+		scores = best[:g.K]
+	}
+
+	var sum float64
+	for _, score := range scores {
+		sum += float64(score)
+	}
+	if g.Aggregation.OrDefault() == AggregateAvg && len(scores) > 0 {
+		return sum / float64(len(scores))
+	}
+	return sum
+}
+
+// Standing is a group's entry on the group leaderboard of a board.
+type Standing struct {
+	GroupID string  `json:"group_id"` // Group the entry belongs to
+	Name    string  `json:"name"`     // Name of the group
+	Score   float64 `json:"score"`    // Aggregated score of the group's members on the board
+	Rank    int64   `json:"rank"`     // Position of the group on the leaderboard, starting at 1
+}
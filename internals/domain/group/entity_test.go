@@ -0,0 +1,41 @@
+package group
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		name   string
+		group  Group
+		scores []int
+		want   float64
+	}{
+		{"sum", Group{Aggregation: AggregateSum}, []int{10, 20, 5}, 35},
+		{"default is sum", Group{}, []int{10, 20, 5}, 35},
+		{"sum without members", Group{Aggregation: AggregateSum}, nil, 0},
+		{"sum with negative scores", Group{Aggregation: AggregateSum}, []int{10, -4}, 6},
+		{"avg", Group{Aggregation: AggregateAvg}, []int{10, 20, 5}, 35.0 / 3},
+		{"avg of a single member", Group{Aggregation: AggregateAvg}, []int{7}, 7},
+		{"avg without members", Group{Aggregation: AggregateAvg}, nil, 0},
+		{"top k keeps the best scores", Group{Aggregation: AggregateTopK, K: 2}, []int{5, 30, 10, 20}, 50},
+		{"top k with ties", Group{Aggregation: AggregateTopK, K: 2}, []int{10, 10, 10}, 20},
+		{"top k with fewer members than k", Group{Aggregation: AggregateTopK, K: 5}, []int{5, 30}, 35},
+		{"top k with exactly k members", Group{Aggregation: AggregateTopK, K: 2}, []int{5, 30}, 35},
+		{"top k prefers higher over negative", Group{Aggregation: AggregateTopK, K: 1}, []int{-5, 3, -1}, 3},
+		{"top k without members", Group{Aggregation: AggregateTopK, K: 3}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := append([]int(nil), tt.scores...)
+			if got := tt.group.Aggregate(scores); got != tt.want {
+				t.Errorf("Aggregate(%v) = %v, want %v", tt.scores, got, tt.want)
+			}
+			if !reflect.DeepEqual(scores, tt.scores) && len(tt.scores) > 0 {
+				t.Errorf("Aggregate reordered the member scores to %v", scores)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import "time"
+
+// Status is the delivery state of an outbox entry.
+type Status string
+
+const (
+	StatusPending Status = "pending" // Waiting to be applied to the cache, possibly after a failed attempt
+	StatusDead    Status = "dead"    // Given up after MaxAttempts failed attempts, kept for inspection and manual retry
+	StatusApplied Status = "applied" // Applied to the cache, kept for AppliedRetention so cache rebuilds can replay it
+)
+
+// MaxAttempts is the number of failed attempts after which an entry is moved to the dead letters.
+const MaxAttempts = 10
+
+// AppliedRetention is how long applied entries are kept, far longer than any cache rebuild. A rebuild copies the
+// players of the entries written while it ran again, so changes applied to the leaderboard it replaces are not lost.
+const AppliedRetention = time.Hour
+
+// Entry records that the score of a player changed in a scope (a board or one of its windows or attribute leaderboards)
+// and that the cached leaderboard of the scope has to follow. It is written in the same database transaction as the
+// score, so no change is lost, and applied to the cache by the outbox relay, which always copies the player's current
+// score. Entries therefore carry no score and may be applied more than once.
+type Entry struct {
+	ID            string    `json:"id" bson:"_id"`                          // ID of the entry, IDs sort in the order the entries were written
+	ScopeID       string    `json:"scope_id" bson:"scope_id"`               // Scope whose score changed
+	PlayerID      string    `json:"player_id" bson:"player_id"`             // Player whose score changed
+	Status        Status    `json:"status" bson:"status"`                   // Delivery state of the entry
+	Attempts      int       `json:"attempts" bson:"attempts"`               // Number of failed attempts to apply the entry
+	LastError     string    `json:"last_error,omitempty" bson:"last_error"` // Error of the latest failed attempt
+	CreatedAt     time.Time `json:"created_at" bson:"created_at"`           // Time the score changed
+	NextAttemptAt time.Time `json:"next_attempt_at" bson:"next_attempt_at"` // Earliest time the relay applies the entry
+	ExpiresAt     time.Time `json:"-" bson:"expires_at,omitempty"`          // Time an applied entry is removed
+}
+
+// NewEntry returns a pending entry for a change of the player's score in the scope. The ID is set by the repository.
+func NewEntry(scopeID, playerID string, createdAt time.Time) Entry {
+	return Entry{ScopeID: scopeID, PlayerID: playerID, Status: StatusPending, CreatedAt: createdAt, NextAttemptAt: createdAt}
+}
+
+// NewDeadEntry returns an entry that goes straight to the dead letters, for a change of the player's score in the scope
+// that cannot be applied until an operator steps in, such as a score written by another application to a board that
+// does not exist. Retrying it once the cause is fixed applies the change. The ID is set by the repository.
+func NewDeadEntry(scopeID, playerID string, err error, createdAt time.Time) Entry {
+	e := NewEntry(scopeID, playerID, createdAt)
+	e.Status, e.Attempts, e.LastError = StatusDead, 1, err.Error()
+	return e
+}
+
+// Fail records a failed attempt to apply the entry. The next attempt is delayed exponentially, starting at one second
+// and capped at five minutes, and the entry is moved to the dead letters after MaxAttempts attempts.
+func (e *Entry) Fail(err error, now time.Time) {
+	e.Attempts++
+	e.LastError = err.Error()
+
+	delay := time.Second << (e.Attempts - 1)
+	if delay > 5*time.Minute || delay <= 0 {
+		delay = 5 * time.Minute
+	}
+	e.NextAttemptAt = now.Add(delay)
+
+	if e.Attempts >= MaxAttempts {
+		e.Status = StatusDead
+	}
+}
+
+// Key identifies the player and scope of the entry. Entries with the same key are applied in the order they were written.
+func (e Entry) Key() string {
+	return e.ScopeID + "\x00" + e.PlayerID
+}
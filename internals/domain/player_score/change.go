@@ -0,0 +1,9 @@
+package player_score
+
+// ScoreChange describes the effect of a write on a player's stored score.
+type ScoreChange struct {
+	OldScore int  `json:"old_score"` // Score before the write, zero when the player had no score
+	NewScore int  `json:"new_score"` // Score after the write
+	Created  bool `json:"created"`   // Whether the write created the player's score
+	Replaced bool `json:"replaced"`  // Whether the submitted score replaced the stored one, false when the update policy or an increment's bound kept it
+}
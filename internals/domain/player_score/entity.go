@@ -1,10 +1,14 @@
 package player_score
 
+import "time"
+
 // PlayerScore represents the structure for storing player information and their score.
 // It includes the player's ID, name, and current score, with corresponding JSON and BSON annotations
 // for serialization and storage in MongoDB.
 type PlayerScore struct {
-	PlayerID   string `json:"player_id" bson:"player_id"`     // Unique identifier for the player
-	PlayerName string `json:"player_name" bson:"player_name"` // Player's name
-	Score      int    `json:"score" bson:"score"`             // Player's current score
+	PlayerID       string    `json:"player_id" bson:"player_id"`                   // Unique identifier for the player
+	PlayerName     string    `json:"player_name" bson:"player_name"`               // Player's name
+	Score          int       `json:"score" bson:"score"`                           // Player's current score
+	AchievedAt     time.Time `json:"achieved_at" bson:"achieved_at"`               // Time the current score was reached, set by the service
+	CompletionTime int64     `json:"completion_ms,omitempty" bson:"completion_ms"` // Time taken to complete the quiz in milliseconds, breaks ties on "fastest" boards
 }
@@ -0,0 +1,37 @@
+package player_score
+
+import "time"
+
+// Increment describes an atomic change of a player's score by a delta, optionally kept within limits.
+// A negative delta decrements the score.
+type Increment struct {
+	PlayerID   string    `json:"player_id"`         // Player whose score changes
+	PlayerName string    `json:"player_name"`       // Optional new name of the player, the stored name is kept when empty
+	Delta      int       `json:"delta"`             // Amount added to the score
+	Floor      *int      `json:"floor,omitempty"`   // Optional lowest score the result may have
+	Ceiling    *int      `json:"ceiling,omitempty"` // Optional highest score the result may have
+	AchievedAt time.Time `json:"-"`                 // Time of the change, set by the service
+}
+
+// Bounded reports whether the increment limits the resulting score.
+func (inc Increment) Bounded() bool {
+	return inc.Floor != nil || inc.Ceiling != nil
+}
+
+// Apply returns the score resulting from applying the increment to the given score.
+func (inc Increment) Apply(score int) int {
+	score += inc.Delta
+	if inc.Floor != nil && score < *inc.Floor {
+		score = *inc.Floor
+	}
+	if inc.Ceiling != nil && score > *inc.Ceiling {
+		score = *inc.Ceiling
+	}
+	return score
+}
+
+// Changes reports whether applying the increment changes the given score, which is not the case when the score already
+// is at the bound the increment moves it towards.
+func (inc Increment) Changes(score int) bool {
+	return inc.Apply(score) != score
+}
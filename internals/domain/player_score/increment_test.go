@@ -0,0 +1,64 @@
+package player_score
+
+import "testing"
+
+func TestIncrementApply(t *testing.T) {
+	bound := func(v int) *int { return &v }
+
+	tests := []struct {
+		name  string
+		inc   Increment
+		score int
+		want  int
+	}{
+		{"unbounded increment", Increment{Delta: 5}, 10, 15},
+		{"unbounded decrement below zero", Increment{Delta: -15}, 10, -5},
+		{"zero delta", Increment{Delta: 0}, 10, 10},
+		{"floor is not reached", Increment{Delta: -3, Floor: bound(0)}, 10, 7},
+		{"floor stops a decrement", Increment{Delta: -15, Floor: bound(0)}, 10, 0},
+		{"result equal to the floor", Increment{Delta: -10, Floor: bound(0)}, 10, 0},
+		{"ceiling is not reached", Increment{Delta: 3, Ceiling: bound(100)}, 90, 93},
+		{"ceiling stops an increment", Increment{Delta: 30, Ceiling: bound(100)}, 90, 100},
+		{"result equal to the ceiling", Increment{Delta: 10, Ceiling: bound(100)}, 90, 100},
+		{"score already below the floor is raised", Increment{Delta: 1, Floor: bound(10)}, 2, 10},
+		{"score already above the ceiling is lowered", Increment{Delta: -1, Ceiling: bound(10)}, 20, 10},
+		{"both bounds, within", Increment{Delta: 5, Floor: bound(0), Ceiling: bound(100)}, 50, 55},
+		{"both bounds, below", Increment{Delta: -80, Floor: bound(0), Ceiling: bound(100)}, 50, 0},
+		{"both bounds, above", Increment{Delta: 80, Floor: bound(0), Ceiling: bound(100)}, 50, 100},
+		{"negative floor", Increment{Delta: -20, Floor: bound(-10)}, 0, -10},
+		{"score already at the ceiling", Increment{Delta: 5, Ceiling: bound(100)}, 100, 100},
+		{"score already at the floor", Increment{Delta: -5, Floor: bound(0)}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.inc.Apply(tt.score); got != tt.want {
+				t.Errorf("Apply(%d) = %d, want %d", tt.score, got, tt.want)
+			}
+			if got := tt.inc.Changes(tt.score); got != (tt.want != tt.score) {
+				t.Errorf("Changes(%d) = %v, want %v", tt.score, got, tt.want != tt.score)
+			}
+		})
+	}
+}
+
+func TestIncrementBounded(t *testing.T) {
+	bound := 0
+	tests := []struct {
+		name string
+		inc  Increment
+		want bool
+	}{
+		{"no bounds", Increment{Delta: 1}, false},
+		{"floor", Increment{Delta: 1, Floor: &bound}, true},
+		{"ceiling", Increment{Delta: 1, Ceiling: &bound}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.inc.Bounded(); got != tt.want {
+				t.Errorf("Bounded() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
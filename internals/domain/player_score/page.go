@@ -0,0 +1,96 @@
+package player_score
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrInvalidPageToken is returned when a page token cannot be decoded into a Cursor.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// Cursor identifies the last entry of a page, the next page starts right after it.
+// It holds every field a TieBreak orders by, so it is enough to find the position of the next page
+// even when players above it change.
+type Cursor struct {
+	Score          int    `json:"s"`           // Score of the last entry of the previous page
+	AchievedAt     int64  `json:"a,omitempty"` // Unix time the last entry reached its score
+	CompletionTime int64  `json:"c,omitempty"` // Completion time of the last entry in milliseconds
+	PlayerID       string `json:"p"`           // Player ID of the last entry of the previous page
+}
+
+// PageRequest describes which part of a leaderboard should be returned.
+// When After is set the page starts right after the cursor and Offset is ignored.
+// A Limit of zero or less returns everything from the starting point onwards.
+type PageRequest struct {
+	Offset int64   // Number of entries to skip from the top of the leaderboard
+	Limit  int64   // Maximum number of entries to return
+	After  *Cursor // Optional cursor of the last entry of the previous page
+}
+
+// Page is a single page of a leaderboard together with the information needed to fetch the next one.
+type Page struct {
+	Players       []RankedPlayerScore `json:"top_players"`               // Entries of this page in leaderboard order
+	Total         int64               `json:"total"`                     // Total number of players on the leaderboard
+	NextPageToken string              `json:"next_page_token,omitempty"` // Token of the next page, empty on the last page
+}
+
+// CursorOf returns the cursor pointing right after the given player.
+func CursorOf(player PlayerScore) Cursor {
+	return Cursor{
+		Score:          player.Score,
+		AchievedAt:     player.AchievedAt.Unix(),
+		CompletionTime: player.CompletionTime,
+		PlayerID:       player.PlayerID,
+	}
+}
+
+// Player returns the ordering fields of the cursor as a PlayerScore, so it can be compared with TieBreak.Before.
+func (c Cursor) Player() PlayerScore {
+	return PlayerScore{
+		PlayerID:       c.PlayerID,
+		Score:          c.Score,
+		AchievedAt:     time.Unix(c.AchievedAt, 0).UTC(),
+		CompletionTime: c.CompletionTime,
+	}
+}
+
+// EncodeCursor turns a cursor into an opaque, URL safe page token.
+func EncodeCursor(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a page token created by EncodeCursor.
+func DecodeCursor(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidPageToken
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.PlayerID == "" {
+		return Cursor{}, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
+
+// Slice applies the page request to a leaderboard that is already sorted with tb.Before.
+func (p PageRequest) Slice(tb TieBreak, sorted []PlayerScore) []PlayerScore {
+	start := p.Offset
+	if p.After != nil {
+		after := p.After.Player()
+		start = int64(sort.Search(len(sorted), func(i int) bool { return tb.Before(after, sorted[i]) }))
+	}
+	if start < 0 || start >= int64(len(sorted)) {
+		return []PlayerScore{}
+	}
+
+	end := int64(len(sorted))
+	if p.Limit > 0 && start+p.Limit < end {
+		end = start + p.Limit
+	}
+	return sorted[start:end]
+}
@@ -0,0 +1,152 @@
+package player_score
+
+import (
+	"encoding/base64"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	achievedAt := time.Date(2026, time.October, 16, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		player PlayerScore
+	}{
+		{"earliest fields", PlayerScore{PlayerID: "p1", Score: 10, AchievedAt: achievedAt}},
+		{"completion time", PlayerScore{PlayerID: "p2", Score: 10, AchievedAt: achievedAt, CompletionTime: 4200}},
+		{"negative score", PlayerScore{PlayerID: "p3", Score: -3, AchievedAt: achievedAt}},
+		{"url unsafe player ID", PlayerScore{PlayerID: "a/b+c?d=é", Score: 1, AchievedAt: achievedAt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := CursorOf(tt.player)
+			decoded, err := DecodeCursor(EncodeCursor(cursor))
+			if err != nil {
+				t.Fatalf("DecodeCursor(EncodeCursor(%+v)) error = %v", cursor, err)
+			}
+			if decoded != cursor {
+				t.Errorf("DecodeCursor(EncodeCursor(%+v)) = %+v", cursor, decoded)
+			}
+
+			got := decoded.Player()
+			if got.PlayerID != tt.player.PlayerID || got.Score != tt.player.Score || !got.AchievedAt.Equal(tt.player.AchievedAt) || got.CompletionTime != tt.player.CompletionTime {
+				t.Errorf("Cursor.Player() = %+v, want the ordering fields of %+v", got, tt.player)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"empty", ""},
+		{"not base64", "!!!"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("not json"))},
+		{"missing player ID", base64.RawURLEncoding.EncodeToString([]byte(`{"s":10}`))},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":10,"p":"p1"}`)) + "="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.token); err != ErrInvalidPageToken {
+				t.Errorf("DecodeCursor(%q) error = %v, want %v", tt.token, err, ErrInvalidPageToken)
+			}
+		})
+	}
+}
+
+func TestPageRequestSlice(t *testing.T) {
+	base := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	sorted := []PlayerScore{
+		{PlayerID: "a", Score: 50, AchievedAt: base},
+		{PlayerID: "b", Score: 40, AchievedAt: base},
+		{PlayerID: "c", Score: 40, AchievedAt: base.Add(2 * time.Second)},
+		{PlayerID: "d", Score: 30, AchievedAt: base},
+		{PlayerID: "e", Score: 20, AchievedAt: base},
+	}
+	after := func(player PlayerScore) *Cursor {
+		cursor := CursorOf(player)
+		return &cursor
+	}
+
+	tests := []struct {
+		name string
+		page PageRequest
+		want []string
+	}{
+		{"everything", PageRequest{}, []string{"a", "b", "c", "d", "e"}},
+		{"first page", PageRequest{Limit: 2}, []string{"a", "b"}},
+		{"offset page", PageRequest{Offset: 2, Limit: 2}, []string{"c", "d"}},
+		{"last partial page", PageRequest{Offset: 4, Limit: 2}, []string{"e"}},
+		{"offset past the end", PageRequest{Offset: 5, Limit: 2}, []string{}},
+		{"negative offset", PageRequest{Offset: -1, Limit: 2}, []string{}},
+		{"after a member", PageRequest{Limit: 2, After: after(sorted[1])}, []string{"c", "d"}},
+		{"after the last member", PageRequest{Limit: 2, After: after(sorted[4])}, []string{}},
+		{"cursor ignores the offset", PageRequest{Offset: 4, After: after(sorted[0])}, []string{"b", "c", "d", "e"}},
+		{"after a member whose score changed", PageRequest{Limit: 2, After: after(PlayerScore{PlayerID: "x", Score: 35, AchievedAt: base})}, []string{"d", "e"}},
+		{"after a tie that sorts between members", PageRequest{After: after(PlayerScore{PlayerID: "x", Score: 40, AchievedAt: base.Add(time.Second)})}, []string{"c", "d", "e"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.page.Slice(TieBreakEarliest, sorted)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Slice(%+v) = %v, want %v", tt.page, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursorPagesVisitEveryPlayerOnce(t *testing.T) {
+	base := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	var players []PlayerScore
+	for i := 0; i < 23; i++ {
+		players = append(players, PlayerScore{
+			PlayerID:       string(rune('a' + i)),
+			Score:          i % 4,
+			AchievedAt:     base.Add(time.Duration(i%3) * time.Second),
+			CompletionTime: int64(i % 5),
+		})
+	}
+
+	for _, tb := range []TieBreak{TieBreakEarliest, TieBreakFastest, TieBreakDense, TieBreakStandard} {
+		t.Run(string(tb), func(t *testing.T) {
+			sorted := append([]PlayerScore(nil), players...)
+			sort.Slice(sorted, func(i, j int) bool { return tb.Before(sorted[i], sorted[j]) })
+
+			var visited []string
+			page := PageRequest{Limit: 5}
+			for {
+				players := page.Slice(tb, sorted)
+				if len(players) == 0 {
+					break
+				}
+				visited = append(visited, ids(players)...)
+
+				cursor, err := DecodeCursor(EncodeCursor(CursorOf(players[len(players)-1])))
+				if err != nil {
+					t.Fatalf("DecodeCursor error = %v", err)
+				}
+				page = PageRequest{Limit: 5, After: &cursor}
+			}
+
+			if want := ids(sorted); !reflect.DeepEqual(visited, want) {
+				t.Errorf("cursor pages visited %v, want %v", visited, want)
+			}
+		})
+	}
+}
+
+// ids returns the player IDs of the players in order.
+func ids(players []PlayerScore) []string {
+	result := make([]string, len(players))
+	for i, player := range players {
+		result[i] = player.PlayerID
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package player_score
+
+// UpdatePolicy is the policy deciding whether a submitted score replaces the player's stored score.
+type UpdatePolicy string
+
+const (
+	UpdateKeepLatest UpdatePolicy = "keep_latest" // Every submitted score replaces the stored one
+	UpdateKeepBest   UpdatePolicy = "keep_best"   // A submitted score only replaces a lower stored score
+	UpdateKeepLowest UpdatePolicy = "keep_lowest" // A submitted score only replaces a higher stored score, e.g. for time based quizzes
+
+	DefaultUpdatePolicy = UpdateKeepLatest // Policy used by boards created without one
+)
+
+// Valid reports whether the policy is one of the supported policies.
+func (up UpdatePolicy) Valid() bool {
+	switch up {
+	case UpdateKeepLatest, UpdateKeepBest, UpdateKeepLowest:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the policy, or DefaultUpdatePolicy when it is not set.
+func (up UpdatePolicy) OrDefault() UpdatePolicy {
+	if up == "" {
+		return DefaultUpdatePolicy
+	}
+	return up
+}
+
+// Replaces reports whether a submitted score replaces the stored one under the policy.
+// Equal scores never replace each other under keep_best and keep_lowest, so the first attempt reaching a score is kept.
+func (up UpdatePolicy) Replaces(stored, submitted int) bool {
+	switch up.OrDefault() {
+	case UpdateKeepBest:
+		return submitted > stored
+	case UpdateKeepLowest:
+		return submitted < stored
+	}
+	return true
+}
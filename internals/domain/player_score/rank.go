@@ -0,0 +1,29 @@
+package player_score
+
+// PlayerRank describes where a player stands on a leaderboard.
+type PlayerRank struct {
+	PlayerID   string  `json:"player_id"`  // Unique identifier for the player
+	Rank       int64   `json:"rank"`       // Position on the leaderboard, starting at 1 for the top player
+	Score      int     `json:"score"`      // Player's current score
+	Percentile float64 `json:"percentile"` // Percentage of players ranked at or below the player
+	Total      int64   `json:"total"`      // Total number of players on the leaderboard
+}
+
+// NewPlayerRank builds a PlayerRank and computes its percentile from the rank and total.
+func NewPlayerRank(playerID string, rank int64, score int, total int64) PlayerRank {
+	result := PlayerRank{PlayerID: playerID, Rank: rank, Score: score, Total: total}
+	if total > 0 {
+		result.Percentile = float64(total-rank+1) / float64(total) * 100
+	}
+	return result
+}
+
+// RankedPlayerScore is a leaderboard entry together with its absolute position on the leaderboard.
+// The profile fields are not stored with the entry, they are joined from the player's profile when it is served.
+type RankedPlayerScore struct {
+	PlayerScore `bson:",inline"`
+	Rank        int64  `json:"rank" bson:"rank"`              // Position on the leaderboard, starting at 1 for the top player
+	AvatarURL   string `json:"avatar_url,omitempty" bson:"-"` // Avatar of the player, joined from their profile when the entry is served
+	Country     string `json:"country,omitempty" bson:"-"`    // Country of the player, joined from their profile when the entry is served
+	Region      string `json:"region,omitempty" bson:"-"`     // Region of the player, joined from their profile when the entry is served
+}
@@ -0,0 +1,116 @@
+package player_score
+
+import (
+	"math"
+	"time"
+)
+
+// TieBreak is the policy deciding how players with equal scores are ordered and ranked.
+type TieBreak string
+
+const (
+	TieBreakEarliest TieBreak = "earliest" // The player who reached the score first is ranked higher
+	TieBreakFastest  TieBreak = "fastest"  // The player with the lowest completion time is ranked higher
+	TieBreakDense    TieBreak = "dense"    // Equal scores share a rank and the next rank follows directly (1, 1, 2)
+	TieBreakStandard TieBreak = "standard" // Equal scores share a rank and the next rank skips the tied ones (1, 1, 3)
+
+	DefaultTieBreak = TieBreakEarliest // Policy used by boards created without one
+)
+
+// tieEpoch is the origin of the achievement times encoded into sort values.
+var tieEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// tieRange is the number of distinct tie values that fit into the fractional part of a sort value.
+// Sort values keep the score in the integer part, so ties are exact for scores below 2^21 in magnitude.
+const tieRange = 1 << 32
+
+// Valid reports whether the policy is one of the supported policies.
+func (tb TieBreak) Valid() bool {
+	switch tb {
+	case TieBreakEarliest, TieBreakFastest, TieBreakDense, TieBreakStandard:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the policy, or DefaultTieBreak when it is not set.
+func (tb TieBreak) OrDefault() TieBreak {
+	if tb == "" {
+		return DefaultTieBreak
+	}
+	return tb
+}
+
+// Shared reports whether players with equal scores share the same rank.
+func (tb TieBreak) Shared() bool {
+	return tb == TieBreakDense || tb == TieBreakStandard
+}
+
+// Field returns the stored field that breaks ties under the policy, or an empty string when ties share a rank.
+func (tb TieBreak) Field() string {
+	switch tb.OrDefault() {
+	case TieBreakEarliest:
+		return "achieved_at"
+	case TieBreakFastest:
+		return "completion_ms"
+	}
+	return ""
+}
+
+// tieValue returns the value that orders tied players under the policy, lower values rank higher.
+// It is clamped to the range that can be encoded into a sort value.
+func (tb TieBreak) tieValue(player PlayerScore) int64 {
+	var value int64
+	switch tb.OrDefault() {
+	case TieBreakEarliest:
+		value = player.AchievedAt.Unix() - tieEpoch.Unix()
+	case TieBreakFastest:
+		value = player.CompletionTime
+	}
+
+	if value < 0 {
+		return 0
+	}
+	if value >= tieRange {
+		return tieRange - 1
+	}
+	return value
+}
+
+// Before reports whether player a is ranked above player b.
+// Players are ordered by score (highest first), then by the policy's tie value (lowest first) and
+// finally by player ID in descending order, the same way Redis orders equal members of a ZSET.
+func (tb TieBreak) Before(a, b PlayerScore) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	if tieA, tieB := tb.tieValue(a), tb.tieValue(b); tieA != tieB {
+		return tieA < tieB
+	}
+	return a.PlayerID > b.PlayerID
+}
+
+// SortValue returns the value used as the score of the player in a cache ZSET.
+// The integer part is the score and the fractional part encodes the tie value, so that sorting
+// the values in descending order yields the same order as Before.
+func (tb TieBreak) SortValue(player PlayerScore) float64 {
+	fraction := float64(tieRange-1-tb.tieValue(player)) / tieRange
+	if tb.OrDefault().Shared() {
+		fraction = 0
+	}
+	return float64(player.Score) + fraction
+}
+
+// FromSortValue restores the score and the tie breaking field of a player from a sort value created by SortValue.
+func (tb TieBreak) FromSortValue(playerID string, value float64) PlayerScore {
+	player := PlayerScore{PlayerID: playerID, Score: int(math.Floor(value))}
+
+	tie := tieRange - 1 - int64(math.Round((value-math.Floor(value))*tieRange))
+	switch tb.OrDefault() {
+	case TieBreakEarliest:
+		player.AchievedAt = time.Unix(tieEpoch.Unix()+tie, 0).UTC()
+	case TieBreakFastest:
+		player.CompletionTime = tie
+	}
+	return player
+}
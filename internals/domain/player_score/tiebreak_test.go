@@ -0,0 +1,80 @@
+package player_score
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSortValueRoundTrip(t *testing.T) {
+	achievedAt := time.Date(2026, time.October, 16, 12, 30, 45, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		tb     TieBreak
+		player PlayerScore
+		want   PlayerScore
+	}{
+		{"earliest", TieBreakEarliest, PlayerScore{Score: 120, AchievedAt: achievedAt}, PlayerScore{Score: 120, AchievedAt: achievedAt}},
+		{"default is earliest", "", PlayerScore{Score: 7, AchievedAt: achievedAt}, PlayerScore{Score: 7, AchievedAt: achievedAt}},
+		{"earliest drops sub-second precision", TieBreakEarliest, PlayerScore{Score: 3, AchievedAt: achievedAt.Add(999 * time.Millisecond)}, PlayerScore{Score: 3, AchievedAt: achievedAt}},
+		{"earliest before the epoch is clamped", TieBreakEarliest, PlayerScore{Score: 3, AchievedAt: tieEpoch.Add(-time.Hour)}, PlayerScore{Score: 3, AchievedAt: tieEpoch}},
+		{"earliest with a zero score", TieBreakEarliest, PlayerScore{Score: 0, AchievedAt: achievedAt}, PlayerScore{Score: 0, AchievedAt: achievedAt}},
+		{"earliest with a negative score", TieBreakEarliest, PlayerScore{Score: -15, AchievedAt: achievedAt}, PlayerScore{Score: -15, AchievedAt: achievedAt}},
+		{"earliest with the largest exact score", TieBreakEarliest, PlayerScore{Score: 1<<21 - 1, AchievedAt: achievedAt}, PlayerScore{Score: 1<<21 - 1, AchievedAt: achievedAt}},
+		{"fastest", TieBreakFastest, PlayerScore{Score: 80, CompletionTime: 61234}, PlayerScore{Score: 80, CompletionTime: 61234}},
+		{"fastest without a completion time", TieBreakFastest, PlayerScore{Score: 80}, PlayerScore{Score: 80}},
+		{"fastest above the range is clamped", TieBreakFastest, PlayerScore{Score: 80, CompletionTime: tieRange + 5}, PlayerScore{Score: 80, CompletionTime: tieRange - 1}},
+		{"dense keeps only the score", TieBreakDense, PlayerScore{Score: 42, AchievedAt: achievedAt, CompletionTime: 10}, PlayerScore{Score: 42}},
+		{"standard keeps only the score", TieBreakStandard, PlayerScore{Score: -42, AchievedAt: achievedAt, CompletionTime: 10}, PlayerScore{Score: -42}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.player.PlayerID = "player"
+			got := tt.tb.FromSortValue("player", tt.tb.SortValue(tt.player))
+
+			if got.PlayerID != "player" || got.Score != tt.want.Score || got.CompletionTime != tt.want.CompletionTime {
+				t.Errorf("FromSortValue(SortValue(%+v)) = %+v, want %+v", tt.player, got, tt.want)
+			}
+			if tt.tb.Field() == "achieved_at" && !got.AchievedAt.Equal(tt.want.AchievedAt) {
+				t.Errorf("FromSortValue(SortValue(%+v)).AchievedAt = %v, want %v", tt.player, got.AchievedAt, tt.want.AchievedAt)
+			}
+		})
+	}
+}
+
+func TestSortValueOrdersLikeBefore(t *testing.T) {
+	base := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	players := []PlayerScore{
+		{PlayerID: "a", Score: 10, AchievedAt: base.Add(2 * time.Second), CompletionTime: 500},
+		{PlayerID: "b", Score: 10, AchievedAt: base.Add(1 * time.Second), CompletionTime: 900},
+		{PlayerID: "c", Score: 11, AchievedAt: base.Add(5 * time.Second), CompletionTime: 100},
+		{PlayerID: "d", Score: 9, AchievedAt: base, CompletionTime: 50},
+		{PlayerID: "e", Score: 10, AchievedAt: base.Add(1 * time.Second), CompletionTime: 500},
+		{PlayerID: "f", Score: -1, AchievedAt: base, CompletionTime: 50},
+	}
+
+	for _, tb := range []TieBreak{TieBreakEarliest, TieBreakFastest, TieBreakDense, TieBreakStandard} {
+		t.Run(string(tb), func(t *testing.T) {
+			byBefore := append([]PlayerScore(nil), players...)
+			sort.Slice(byBefore, func(i, j int) bool { return tb.Before(byBefore[i], byBefore[j]) })
+
+			// Equal sort values are ordered by player ID in descending order, as in a ZSET
+			byValue := append([]PlayerScore(nil), players...)
+			sort.Slice(byValue, func(i, j int) bool {
+				vi, vj := tb.SortValue(byValue[i]), tb.SortValue(byValue[j])
+				if vi != vj {
+					return vi > vj
+				}
+				return byValue[i].PlayerID > byValue[j].PlayerID
+			})
+
+			for i := range byBefore {
+				if byBefore[i].PlayerID != byValue[i].PlayerID {
+					t.Fatalf("order by sort value = %v, want %v", ids(byValue), ids(byBefore))
+				}
+			}
+		})
+	}
+}
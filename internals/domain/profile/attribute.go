@@ -0,0 +1,52 @@
+package profile
+
+import (
+	"errors"
+	"quiz/internals/domain/window"
+)
+
+// Attribute is a profile field leaderboards can be filtered by. Every board keeps one leaderboard per value of
+// every attribute, e.g. one per country, holding the all-time scores of the players with that value.
+type Attribute string
+
+const (
+	AttributeCountry Attribute = "country" // ISO 3166-1 alpha-2 country code of the player
+	AttributeRegion  Attribute = "region"  // Free-form region code of the player, e.g. "EMEA" or "US-CA"
+)
+
+// Attributes lists every attribute leaderboards can be filtered by.
+var Attributes = []Attribute{AttributeCountry, AttributeRegion}
+
+// ErrInvalidAttribute is returned when an attribute name is not one of the supported attributes.
+var ErrInvalidAttribute = errors.New("attribute must be one of \"country\" or \"region\"")
+
+// Valid reports whether the attribute is one of the supported attributes.
+func (a Attribute) Valid() bool {
+	switch a {
+	case AttributeCountry, AttributeRegion:
+		return true
+	}
+	return false
+}
+
+// Value returns the profile's value of the attribute, empty when it is not set.
+func (p Profile) Value(a Attribute) string {
+	switch a {
+	case AttributeCountry:
+		return p.Country
+	case AttributeRegion:
+		return p.Region
+	}
+	return ""
+}
+
+// ScopePrefix returns the prefix shared by the scope IDs of every value of the attribute on the board.
+func ScopePrefix(boardID string, a Attribute) string {
+	return window.ScopePrefix(boardID) + string(a) + ":"
+}
+
+// ScopeID returns the ID under which the scores of the board's players with the attribute value are stored,
+// e.g. "quiz-1@country:DE". Scope IDs share the board's window prefix, so they are removed together with the board.
+func ScopeID(boardID string, a Attribute, value string) string {
+	return ScopePrefix(boardID, a) + value
+}
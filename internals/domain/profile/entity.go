@@ -0,0 +1,41 @@
+package profile
+
+import "time"
+
+// Profile holds the details of a player that are shared by every board. Leaderboards show the display name
+// of the profile, so renaming a player does not require rewriting any of their scores.
+type Profile struct {
+	PlayerID    string    `json:"player_id" bson:"_id"`                   // Unique identifier of the player, the same ID used on every board
+	DisplayName string    `json:"display_name" bson:"display_name"`       // Name shown for the player on leaderboards
+	AvatarURL   string    `json:"avatar_url,omitempty" bson:"avatar_url"` // Optional URL of the player's avatar image
+	Country     string    `json:"country,omitempty" bson:"country"`       // Optional ISO 3166-1 alpha-2 code of the player's country
+	Region      string    `json:"region,omitempty" bson:"region"`         // Optional region code of the player, e.g. "EMEA" or "US-CA"
+	CreatedAt   time.Time `json:"created_at" bson:"created_at"`           // Time the profile was created
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at"`           // Time the profile was last changed
+}
+
+// Update lists the profile fields to change, fields left nil keep their current value.
+// An empty AvatarURL, Country or Region clears the field.
+type Update struct {
+	DisplayName *string `json:"display_name"` // New display name
+	AvatarURL   *string `json:"avatar_url"`   // New avatar URL
+	Country     *string `json:"country"`      // New country code
+	Region      *string `json:"region"`       // New region code
+}
+
+// Apply returns the profile with the changes of the update applied.
+func (u Update) Apply(p Profile) Profile {
+	if u.DisplayName != nil {
+		p.DisplayName = *u.DisplayName
+	}
+	if u.AvatarURL != nil {
+		p.AvatarURL = *u.AvatarURL
+	}
+	if u.Country != nil {
+		p.Country = *u.Country
+	}
+	if u.Region != nil {
+		p.Region = *u.Region
+	}
+	return p
+}
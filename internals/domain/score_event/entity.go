@@ -0,0 +1,23 @@
+package score_event
+
+import "time"
+
+// ScoreEvent is an append-only record of a single change to a player's score on a board.
+// Events are never updated or deleted, so they explain how a score came to be.
+type ScoreEvent struct {
+	BoardID   string    `json:"board_id" bson:"board_id"`     // Board the score belongs to
+	PlayerID  string    `json:"player_id" bson:"player_id"`   // Player whose score changed
+	OldScore  int       `json:"old_score" bson:"old_score"`   // Score before the change, zero when the score was created
+	NewScore  int       `json:"new_score" bson:"new_score"`   // Score after the change
+	Delta     int       `json:"delta" bson:"delta"`           // Difference between the new and the old score
+	Created   bool      `json:"created" bson:"created"`       // Whether the change created the player's score on the board
+	Source    string    `json:"source" bson:"source"`         // Operation that caused the change, e.g. "add_or_update"
+	RequestID string    `json:"request_id" bson:"request_id"` // ID of the request that caused the change
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`   // Time the change was recorded
+}
+
+// Origin describes where a score change comes from, it is copied into the ScoreEvent.
+type Origin struct {
+	Source    string // Operation that caused the change
+	RequestID string // ID of the request that caused the change
+}
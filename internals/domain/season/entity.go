@@ -0,0 +1,20 @@
+package season
+
+import "time"
+
+// Season is a period of play on a board. While a season is active the board's live leaderboard belongs to it,
+// when it ends the leaderboard is archived as the season's final standings and the live scores are reset.
+type Season struct {
+	BoardID   string     `json:"board_id" bson:"board_id"`                     // Board the season is played on
+	ID        string     `json:"id" bson:"season_id"`                          // Identifier of the season, unique per board
+	Name      string     `json:"name" bson:"name"`                             // Human readable name of the season
+	Active    bool       `json:"active" bson:"active"`                         // Whether the season is still running, a board has at most one active season
+	StartedAt time.Time  `json:"started_at" bson:"started_at"`                 // Time the season started
+	EndedAt   *time.Time `json:"ended_at,omitempty" bson:"ended_at,omitempty"` // Time the season ended, nil while it is active
+	Total     int64      `json:"total" bson:"total"`                           // Number of players in the final standings, zero while it is active
+}
+
+// ArchiveID returns the ID under which the final standings of the season are archived.
+func (s Season) ArchiveID() string {
+	return "season:" + s.ID
+}
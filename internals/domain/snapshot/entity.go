@@ -0,0 +1,40 @@
+package snapshot
+
+import "time"
+
+// Snapshot is a named capture of a board's ranking at one moment, e.g. at the end of a live quiz show.
+// The ranked players are archived as standings and never change afterwards.
+type Snapshot struct {
+	BoardID  string    `json:"board_id" bson:"board_id"` // Board the snapshot was taken of
+	Name     string    `json:"name" bson:"name"`         // Name of the snapshot, unique per board
+	TakenAt  time.Time `json:"taken_at" bson:"taken_at"` // Time the snapshot was taken
+	Complete bool      `json:"complete" bson:"complete"` // Whether every ranked player has been archived
+	Total    int64     `json:"total" bson:"total"`       // Number of players in the snapshot
+}
+
+// ArchiveID returns the ID under which the ranked players of the snapshot are archived.
+func (s Snapshot) ArchiveID() string {
+	return "snapshot:" + s.Name
+}
+
+// Status of a player in the comparison of two snapshots.
+const (
+	MovementUp      = "up"      // The player ranks higher in the later snapshot
+	MovementDown    = "down"    // The player ranks lower in the later snapshot
+	MovementSame    = "same"    // The player keeps the rank
+	MovementNew     = "new"     // The player is only part of the later snapshot
+	MovementDropped = "dropped" // The player is only part of the earlier snapshot
+)
+
+// Movement describes how a player's rank changed between two snapshots.
+// Ranks and scores are nil for the snapshot the player is missing from.
+type Movement struct {
+	PlayerID   string `json:"player_id"`            // Player the movement belongs to
+	PlayerName string `json:"player_name"`          // Name of the player in the latest snapshot holding them
+	FromRank   *int64 `json:"from_rank,omitempty"`  // Rank in the earlier snapshot
+	ToRank     *int64 `json:"to_rank,omitempty"`    // Rank in the later snapshot
+	FromScore  *int   `json:"from_score,omitempty"` // Score in the earlier snapshot
+	ToScore    *int   `json:"to_score,omitempty"`   // Score in the later snapshot
+	RankChange int64  `json:"rank_change"`          // Number of ranks gained (positive) or lost (negative), zero unless the player is in both snapshots
+	Status     string `json:"status"`               // One of the Movement statuses
+}
@@ -0,0 +1,44 @@
+package social
+
+import (
+	"errors"
+	"time"
+)
+
+// Follow is a directed edge of the social graph: the follower sees the followee on their friends leaderboards.
+// Two players following each other are friends.
+type Follow struct {
+	FollowerID string    `json:"follower_id" bson:"follower_id"` // Player who follows
+	FolloweeID string    `json:"followee_id" bson:"followee_id"` // Player who is followed
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`   // Time the edge was created
+}
+
+// Relation selects which players of the social graph a friends leaderboard ranks next to the player.
+type Relation string
+
+const (
+	RelationFriends   Relation = "friends"   // Players the player follows and who follow them back
+	RelationFollowing Relation = "following" // Every player the player follows
+
+	DefaultRelation = RelationFriends // Relation used when none is given
+)
+
+// ErrInvalidRelation is returned when a relation name is not one of the supported relations.
+var ErrInvalidRelation = errors.New("relation must be one of \"friends\" or \"following\"")
+
+// Valid reports whether the relation is one of the supported relations.
+func (r Relation) Valid() bool {
+	switch r {
+	case RelationFriends, RelationFollowing:
+		return true
+	}
+	return false
+}
+
+// OrDefault returns the relation, or DefaultRelation when it is not set.
+func (r Relation) OrDefault() Relation {
+	if r == "" {
+		return DefaultRelation
+	}
+	return r
+}
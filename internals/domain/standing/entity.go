@@ -0,0 +1,12 @@
+package standing
+
+import "quiz/internals/domain/player_score"
+
+// Standing is one immutable entry of an archived leaderboard, such as the final standings of a season.
+// Archives are identified per board by an archive ID and keep the ranks the players had when they were taken.
+type Standing struct {
+	BoardID                        string `json:"-" bson:"board_id"`   // Board the archive was taken from
+	ArchiveID                      string `json:"-" bson:"archive_id"` // Archive the entry belongs to, e.g. "season:2026-q4"
+	Position                       int64  `json:"-" bson:"position"`   // Position of the entry in the archive, starting at 1 and unique per archive
+	player_score.RankedPlayerScore `bson:",inline"`
+}
@@ -0,0 +1,227 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Window is a recurring period for which a leaderboard is kept next to the all-time leaderboard of a board.
+type Window string
+
+const (
+	AllTime Window = "all_time" // The board itself, never rolls over
+	Daily   Window = "daily"    // Rolls over at midnight
+	Weekly  Window = "weekly"   // Rolls over at midnight between Sunday and Monday (ISO weeks)
+	Monthly Window = "monthly"  // Rolls over at midnight of the first day of the month
+)
+
+// ErrInvalidWindow is returned when a window name is not one of the supported windows.
+var ErrInvalidWindow = errors.New("window must be one of \"all_time\", \"daily\", \"weekly\" or \"monthly\"")
+
+// scopeSeparator separates the board ID from the window in scope IDs. Board IDs cannot contain it,
+// so the scores of a window never mix with the scores of a board.
+const scopeSeparator = "@"
+
+// Valid reports whether the window is one of the supported windows.
+func (w Window) Valid() bool {
+	switch w {
+	case AllTime, Daily, Weekly, Monthly:
+		return true
+	}
+	return false
+}
+
+// Period returns the label of the window's period containing t in the given location,
+// e.g. "2026-10-16" (daily), "2026-W42" (weekly) or "2026-10" (monthly). It is empty for AllTime.
+func (w Window) Period(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	switch w {
+	case Daily:
+		return t.Format("2006-01-02")
+	case Weekly:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Monthly:
+		return t.Format("2006-01")
+	}
+	return ""
+}
+
+// End returns the time the window's period containing t ends in the given location, which is the start of the next
+// period. It is the zero time for AllTime.
+func (w Window) End(t time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	year, month, day := t.Date()
+	switch w {
+	case Daily:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+	case Weekly:
+		return time.Date(year, month, day+8-isoWeekday(t), 0, 0, 0, 0, loc)
+	case Monthly:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+	}
+	return time.Time{}
+}
+
+// isoWeekday returns the day of the week of t, counting from 1 on Monday to 7 on Sunday.
+func isoWeekday(t time.Time) int {
+	if day := int(t.Weekday()); day != 0 {
+		return day
+	}
+	return 7
+}
+
+// ScopeID returns the ID under which the scores of the board are stored for the window's period containing t,
+// e.g. "quiz-1@daily:2026-10-16". Every period gets its own scope, which is how windows roll over.
+// The scope ID of AllTime is the board ID itself.
+func ScopeID(boardID string, w Window, t time.Time, loc *time.Location) string {
+	if w == AllTime {
+		return boardID
+	}
+	return boardID + scopeSeparator + string(w) + ":" + w.Period(t, loc)
+}
+
+// ScopePrefix returns the prefix shared by the scope IDs of every window of the board.
+// Other scopes derived from the board, such as its attribute leaderboards, start with it as well.
+func ScopePrefix(boardID string) string {
+	return boardID + scopeSeparator
+}
+
+// SplitScopeID splits a scope ID into the ID of its board and the rest of the scope, which starts with the separator
+// and is empty when the scope is the board itself.
+func SplitScopeID(scopeID string) (boardID, scope string) {
+	if i := strings.Index(scopeID, scopeSeparator); i >= 0 {
+		return scopeID[:i], scopeID[i:]
+	}
+	return scopeID, ""
+}
+
+// Scope is the scope of one period of a window of a board.
+type Scope struct {
+	ID        string    // Scope ID of the period, e.g. "quiz-1@daily:2026-10-16"
+	ExpiresAt time.Time // Time after which the scores of the period are removed
+}
+
+// Schedule is the set of windows kept for every board and the timezone in which their periods roll over.
+type Schedule struct {
+	Windows   []Window       // Windows updated on every score submission, besides the all-time board
+	Location  *time.Location // Timezone of the period boundaries
+	Retention time.Duration  // Time the scores of a period are kept after it ended
+}
+
+// ParseSchedule parses a comma separated list of windows and an IANA timezone name into a Schedule keeping the
+// scores of every period for the retention after it ended. The all-time window is always kept, so listing it has no effect.
+func ParseSchedule(windows, timezone string, retention time.Duration) (Schedule, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	schedule := Schedule{Location: loc, Retention: retention}
+	for _, name := range strings.Split(windows, ",") {
+		w := Window(strings.TrimSpace(name))
+		switch {
+		case w == "" || w == AllTime || schedule.Has(w):
+			continue
+		case !w.Valid():
+			return Schedule{}, ErrInvalidWindow
+		}
+		schedule.Windows = append(schedule.Windows, w)
+	}
+	return schedule, nil
+}
+
+// Has reports whether the window is kept by the schedule. The all-time window always is.
+func (s Schedule) Has(w Window) bool {
+	if w == AllTime {
+		return true
+	}
+	for _, scheduled := range s.Windows {
+		if scheduled == w {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeID returns the scope ID of the board for the window's period containing t in the schedule's timezone.
+func (s Schedule) ScopeID(boardID string, w Window, t time.Time) string {
+	return ScopeID(boardID, w, t, s.Location)
+}
+
+// ScopeIDs returns the scope IDs of the board for the periods of every scheduled window containing t.
+func (s Schedule) ScopeIDs(boardID string, t time.Time) []string {
+	scopes := make([]string, len(s.Windows))
+	for i, w := range s.Windows {
+		scopes[i] = s.ScopeID(boardID, w, t)
+	}
+	return scopes
+}
+
+// Scopes returns the scopes of the board for the periods of every scheduled window containing t, each expiring the
+// schedule's retention after its period ends.
+func (s Schedule) Scopes(boardID string, t time.Time) []Scope {
+	scopes := make([]Scope, len(s.Windows))
+	for i, w := range s.Windows {
+		scopes[i] = Scope{ID: s.ScopeID(boardID, w, t), ExpiresAt: w.End(t, s.Location).Add(s.Retention).UTC()}
+	}
+	return scopes
+}
+
+// Scope returns the scope of the window period with the given scope ID, expiring the schedule's retention after the
+// period ends like those of Scopes. It returns false when the scope ID is not the one of a window period, such as the
+// board itself or one of its attribute leaderboards.
+func (s Schedule) Scope(scopeID string) (Scope, bool) {
+	w, start, ok := parsePeriod(scopeID, s.Location)
+	if !ok {
+		return Scope{}, false
+	}
+	return Scope{ID: scopeID, ExpiresAt: w.End(start, s.Location).Add(s.Retention).UTC()}, true
+}
+
+// IsPeriodScope reports whether the scope ID is the one of a window period, see ScopeID.
+func IsPeriodScope(scopeID string) bool {
+	_, _, ok := parsePeriod(scopeID, time.UTC)
+	return ok
+}
+
+// parsePeriod returns the window of a window period's scope ID and the start of its period in the given location.
+func parsePeriod(scopeID string, loc *time.Location) (Window, time.Time, bool) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	_, scope := SplitScopeID(scopeID)
+	name, period, ok := strings.Cut(strings.TrimPrefix(scope, scopeSeparator), ":")
+	if !ok {
+		return "", time.Time{}, false
+	}
+
+	var start time.Time
+	var err error
+	switch w := Window(name); w {
+	case Daily:
+		start, err = time.ParseInLocation("2006-01-02", period, loc)
+	case Monthly:
+		start, err = time.ParseInLocation("2006-01", period, loc)
+	case Weekly:
+		var year, week int
+		_, err = fmt.Sscanf(period, "%d-W%d", &year, &week)
+		// The 4th of January is always in the first ISO week of its year
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+		start = jan4.AddDate(0, 0, (week-1)*7+1-isoWeekday(jan4))
+	default:
+		return "", time.Time{}, false
+	}
+	if err != nil || Window(name).Period(start, loc) != period { // Only the labels Period returns name a period
+		return "", time.Time{}, false
+	}
+	return Window(name), start, true
+}
@@ -0,0 +1,231 @@
+package window
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata" // Loads the zones below without relying on the host's zoneinfo
+)
+
+// mustLoad loads the named location or fails the test.
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q) error = %v", name, err)
+	}
+	return loc
+}
+
+// mustParse parses an RFC 3339 time or fails the test.
+func mustParse(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", value, err)
+	}
+	return parsed
+}
+
+func TestPeriodAndEnd(t *testing.T) {
+	tests := []struct {
+		name       string
+		window     Window
+		timezone   string
+		at         string // Time inside the period
+		wantPeriod string
+		wantEnd    string // End of the period, in UTC
+	}{
+		{"daily in UTC", Daily, "UTC", "2026-10-16T12:00:00Z", "2026-10-16", "2026-10-17T00:00:00Z"},
+		{"daily at midnight starts the day", Daily, "UTC", "2026-10-16T00:00:00Z", "2026-10-16", "2026-10-17T00:00:00Z"},
+		{"daily just before midnight", Daily, "UTC", "2026-10-16T23:59:59Z", "2026-10-16", "2026-10-17T00:00:00Z"},
+		{"daily ahead of UTC is already the next day", Daily, "Asia/Tokyo", "2026-10-16T15:30:00Z", "2026-10-17", "2026-10-17T15:00:00Z"},
+		{"daily behind UTC is still the previous day", Daily, "America/New_York", "2026-10-17T02:00:00Z", "2026-10-16", "2026-10-17T04:00:00Z"},
+		{"daily on a 23 hour day (spring forward)", Daily, "Europe/Berlin", "2026-03-29T12:00:00+02:00", "2026-03-29", "2026-03-29T22:00:00Z"},
+		{"daily the day before spring forward", Daily, "Europe/Berlin", "2026-03-28T12:00:00+01:00", "2026-03-28", "2026-03-28T23:00:00Z"},
+		{"daily on a 25 hour day (fall back)", Daily, "America/New_York", "2026-11-01T23:30:00-05:00", "2026-11-01", "2026-11-02T05:00:00Z"},
+		{"daily in the repeated hour", Daily, "America/New_York", "2026-11-01T01:30:00-05:00", "2026-11-01", "2026-11-02T05:00:00Z"},
+		{"weekly on a Friday", Weekly, "UTC", "2026-10-16T12:00:00Z", "2026-W42", "2026-10-19T00:00:00Z"},
+		{"weekly on a Monday", Weekly, "UTC", "2026-10-19T00:00:00Z", "2026-W43", "2026-10-26T00:00:00Z"},
+		{"weekly on a Sunday", Weekly, "UTC", "2026-10-18T23:59:59Z", "2026-W42", "2026-10-19T00:00:00Z"},
+		{"weekly Sunday in UTC is Monday in Tokyo", Weekly, "Asia/Tokyo", "2026-10-18T20:00:00Z", "2026-W43", "2026-10-25T15:00:00Z"},
+		{"weekly across fall back", Weekly, "Europe/Berlin", "2026-10-23T12:00:00+02:00", "2026-W43", "2026-10-25T23:00:00Z"},
+		{"weekly ISO year differs from the calendar year", Weekly, "UTC", "2027-01-01T12:00:00Z", "2026-W53", "2027-01-04T00:00:00Z"},
+		{"weekly first ISO week starts in December", Weekly, "UTC", "2025-12-29T00:00:00Z", "2026-W01", "2026-01-05T00:00:00Z"},
+		{"monthly", Monthly, "UTC", "2026-10-16T12:00:00Z", "2026-10", "2026-11-01T00:00:00Z"},
+		{"monthly rolls over the year", Monthly, "UTC", "2026-12-31T23:59:59Z", "2026-12", "2027-01-01T00:00:00Z"},
+		{"monthly ahead of UTC is already the next year", Monthly, "Asia/Tokyo", "2026-12-31T23:30:00Z", "2027-01", "2027-01-31T15:00:00Z"},
+		{"monthly across spring forward", Monthly, "Europe/Berlin", "2026-03-01T00:00:00+01:00", "2026-03", "2026-03-31T22:00:00Z"},
+		{"monthly in February of a leap year", Monthly, "UTC", "2028-02-29T12:00:00Z", "2028-02", "2028-03-01T00:00:00Z"},
+		{"all time has no period", AllTime, "UTC", "2026-10-16T12:00:00Z", "", "0001-01-01T00:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoad(t, tt.timezone)
+			at := mustParse(t, tt.at)
+
+			if got := tt.window.Period(at, loc); got != tt.wantPeriod {
+				t.Errorf("Period(%s) = %q, want %q", tt.at, got, tt.wantPeriod)
+			}
+			if got, want := tt.window.End(at, loc), mustParse(t, tt.wantEnd); !got.Equal(want) {
+				t.Errorf("End(%s) = %s, want %s", tt.at, got.UTC().Format(time.RFC3339), tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestEndStartsTheNextPeriod(t *testing.T) {
+	// Walking period by period through a year crossing both DST changes must never skip or repeat a period
+	for _, timezone := range []string{"UTC", "Europe/Berlin", "America/New_York", "Australia/Lord_Howe"} {
+		for _, w := range []Window{Daily, Weekly, Monthly} {
+			t.Run(timezone+"/"+string(w), func(t *testing.T) {
+				loc := mustLoad(t, timezone)
+				at := time.Date(2026, time.January, 1, 12, 0, 0, 0, loc)
+				seen := make(map[string]bool)
+				for at.Year() < 2027 {
+					period := w.Period(at, loc)
+					if seen[period] {
+						t.Fatalf("period %q was reached twice", period)
+					}
+					seen[period] = true
+
+					end := w.End(at, loc)
+					if !end.After(at) {
+						t.Fatalf("End(%s) = %s, want a later time", at, end)
+					}
+					if w.Period(end.Add(-time.Nanosecond), loc) != period {
+						t.Fatalf("the instant before End(%s) is not in period %q", at, period)
+					}
+					at = end
+				}
+			})
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		windows  string
+		timezone string
+		want     []Window
+		wantErr  bool
+	}{
+		{"empty", "", "UTC", nil, false},
+		{"single window", "daily", "UTC", []Window{Daily}, false},
+		{"keeps the listed order", "monthly,daily,weekly", "Europe/Berlin", []Window{Monthly, Daily, Weekly}, false},
+		{"trims whitespace", " daily , weekly ", "UTC", []Window{Daily, Weekly}, false},
+		{"skips all time and duplicates", "all_time,daily,daily,,", "UTC", []Window{Daily}, false},
+		{"rejects unknown windows", "daily,hourly", "UTC", nil, true},
+		{"rejects unknown timezones", "daily", "Mars/Olympus_Mons", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.windows, tt.timezone, time.Hour)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSchedule(%q, %q) error = %v, want error %v", tt.windows, tt.timezone, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(schedule.Windows, tt.want) {
+				t.Errorf("ParseSchedule(%q).Windows = %v, want %v", tt.windows, schedule.Windows, tt.want)
+			}
+			if schedule.Location.String() != tt.timezone || schedule.Retention != time.Hour {
+				t.Errorf("ParseSchedule(%q, %q) = %+v", tt.windows, tt.timezone, schedule)
+			}
+		})
+	}
+}
+
+func TestScheduleScopes(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezone  string
+		retention time.Duration
+		at        string
+		want      []Scope
+	}{
+		{"UTC", "UTC", 24 * time.Hour, "2026-10-16T12:00:00Z", []Scope{
+			{ID: "quiz@daily:2026-10-16", ExpiresAt: time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)},
+			{ID: "quiz@weekly:2026-W42", ExpiresAt: time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)},
+			{ID: "quiz@monthly:2026-10", ExpiresAt: time.Date(2026, time.November, 2, 0, 0, 0, 0, time.UTC)},
+		}},
+		{"ahead of UTC without retention", "Asia/Tokyo", 0, "2026-10-31T16:00:00Z", []Scope{
+			{ID: "quiz@daily:2026-11-01", ExpiresAt: time.Date(2026, time.November, 1, 15, 0, 0, 0, time.UTC)},
+			{ID: "quiz@weekly:2026-W44", ExpiresAt: time.Date(2026, time.November, 1, 15, 0, 0, 0, time.UTC)},
+			{ID: "quiz@monthly:2026-11", ExpiresAt: time.Date(2026, time.November, 30, 15, 0, 0, 0, time.UTC)},
+		}},
+		{"on the day of fall back", "Europe/Berlin", time.Hour, "2026-10-25T12:00:00+01:00", []Scope{
+			{ID: "quiz@daily:2026-10-25", ExpiresAt: time.Date(2026, time.October, 26, 0, 0, 0, 0, time.UTC)},
+			{ID: "quiz@weekly:2026-W43", ExpiresAt: time.Date(2026, time.October, 26, 0, 0, 0, 0, time.UTC)},
+			{ID: "quiz@monthly:2026-10", ExpiresAt: time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule("daily,weekly,monthly", tt.timezone, tt.retention)
+			if err != nil {
+				t.Fatalf("ParseSchedule error = %v", err)
+			}
+
+			got := schedule.Scopes("quiz", mustParse(t, tt.at))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scopes(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+			if ids := schedule.ScopeIDs("quiz", mustParse(t, tt.at)); len(ids) != len(got) || ids[0] != got[0].ID {
+				t.Errorf("ScopeIDs(%s) = %v, want the IDs of %v", tt.at, ids, got)
+			}
+			for _, want := range tt.want {
+				if scope, ok := schedule.Scope(want.ID); !ok || !reflect.DeepEqual(scope, want) {
+					t.Errorf("Scope(%q) = %v, %v, want %v", want.ID, scope, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScopeOfOtherScopes(t *testing.T) {
+	schedule := Schedule{Windows: []Window{Daily, Weekly, Monthly}, Location: time.UTC}
+	for _, scopeID := range []string{
+		"quiz",
+		"quiz@country:DE",
+		"quiz@daily:2026-10-16:rebuild:token",
+		"quiz@daily:2026-13-01",
+		"quiz@weekly:2026-W54",
+		"quiz@weekly:2026-W1",
+		"quiz@all_time:",
+	} {
+		if scope, ok := schedule.Scope(scopeID); ok || IsPeriodScope(scopeID) {
+			t.Errorf("Scope(%q) = %v, want no window period", scopeID, scope)
+		}
+	}
+	if !IsPeriodScope("quiz@weekly:2026-W53") {
+		t.Errorf("IsPeriodScope(%q) = false, want the last week of 2026", "quiz@weekly:2026-W53")
+	}
+}
+
+func TestSplitScopeID(t *testing.T) {
+	tests := []struct {
+		scopeID   string
+		wantBoard string
+		wantScope string
+	}{
+		{"quiz", "quiz", ""},
+		{"quiz@daily:2026-10-16", "quiz", "@daily:2026-10-16"},
+		{"quiz@country:DE", "quiz", "@country:DE"},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scopeID, func(t *testing.T) {
+			boardID, scope := SplitScopeID(tt.scopeID)
+			if boardID != tt.wantBoard || scope != tt.wantScope {
+				t.Errorf("SplitScopeID(%q) = %q, %q, want %q, %q", tt.scopeID, boardID, scope, tt.wantBoard, tt.wantScope)
+			}
+		})
+	}
+}
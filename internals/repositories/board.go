@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"errors"
+	"quiz/internals/domain/board"
+)
+
+var (
+	ErrBoardExists   = errors.New("board already exists") // Returned when creating a board whose ID is already taken
+	ErrBoardNotFound = errors.New("board not found")      // Returned when a board with the given ID does not exist
+)
+
+// IBoardRepository defines the operations for managing leaderboards in the database.
+type IBoardRepository interface {
+	CreateBoard(b board.Board) error              // Create a new board, returns ErrBoardExists if the ID is taken
+	GetBoards() ([]board.Board, error)            // Retrieve all boards
+	GetBoard(boardID string) (board.Board, error) // Retrieve a single board by its ID, returns ErrBoardNotFound if missing
+	DeleteBoard(boardID string) error             // Delete a board together with all of its scores, window scores, score history, seasons, snapshots and standings, returns ErrBoardNotFound if missing
+}
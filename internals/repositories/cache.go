@@ -1,14 +1,56 @@
 package repositories
 
-import "quiz/internals/domain/player_score"
+import (
+	"quiz/internals/domain/player_score"
+	"time"
+)
+
+// MemberScore is a member of a sorted set together with its score.
+type MemberScore struct {
+	Member string  // Member of the sorted set
+	Score  float64 // Score of the member
+}
+
+// CacheExpiry configures the sliding expiry of the cached leaderboards and player HASHes: every access of a key
+// pushes its expiry back by the TTL. A zero TTL keeps the keys until they are deleted. The leaderboards of window
+// periods do not slide, they expire with the scores of their period, see ICacheRepository.ExpireAt.
+type CacheExpiry struct {
+	LeaderboardTTL time.Duration // Time a leaderboard is kept after its last access
+	PlayerTTL      time.Duration // Time a player HASH is kept after its last access
+}
 
 // ICacheRepository defines the operations for interacting with a cache system,
 // specifically for storing and retrieving player scores and leaderboard data.
+// Leaderboard members are scored with TieBreak.SortValue, so their order matches the database.
+// A leaderboard missing from the cache is cold: UpdatePlayerCache and FillPlayerCache leave it missing instead of
+// creating it with only the written player, and the service rebuilds it from the database on its next read.
 type ICacheRepository interface {
-	UpdatePlayerCache(string, player_score.PlayerScore) error           // Update or invalidate the cache for a player's score or leaderboard
-	GetSetByKey(key string) ([]player_score.PlayerScore, error)         // Retrieve the leaderboard (set of player scores) by a cache key
-	GetRecordByKey(key string) (player_score.PlayerScore, error)        // Retrieve a specific player's score from the cache by key
-	InsertRecord(key, playerID, playername string, score float64) error // Insert or update a player's score in the cache
-	Connect()                                                           // Establish a connection to the cache
-	Close()                                                             // Close the cache connection
+	UpdatePlayerCache(key string, tb player_score.TieBreak, up player_score.UpdatePolicy, player player_score.PlayerScore) error // Update the cache for a player's score and details, keeping a better cached score under the keep_best and keep_lowest policies
+	GetSetByKey(key string, tb player_score.TieBreak, page player_score.PageRequest) ([]player_score.PlayerScore, error)         // Retrieve a page of the leaderboard (set of player scores) by a cache key
+	GetSetMembers(key string, tb player_score.TieBreak, playerIDs []string) ([]player_score.PlayerScore, error)                  // Retrieve the entries of the given players in the leaderboard, in leaderboard order, players missing from it are left out
+	GetSetSize(key string) (int64, error)                                                                                        // Count the members of the leaderboard identified by the cache key
+	FillPlayerCache(key string, tb player_score.TieBreak, player player_score.PlayerScore) error                                 // Add a player's score and details read from the database, keeping a cached entry that is already present
+	GetRecordByKey(key string, tb player_score.TieBreak, playerID string) (player_score.PlayerScore, error)                      // Retrieve a player's entry in the leaderboard identified by the cache key, joined with their name
+	GetRank(key, playerID string) (int64, int, error)                                                                            // Retrieve a player's position (starting at 1) and score from the leaderboard identified by the cache key
+	AddToSet(key string, tb player_score.TieBreak, players []player_score.PlayerScore) error                                     // Add a batch of player scores and details to the leaderboard in one round trip, such as a leaderboard being rebuilt
+	ReplaceSet(tempKey, key, generationKey string, generation int64) (bool, error)                                               // Atomically replace the leaderboard with the one built under the temporary key, removing it when the temporary key is empty, unless the generation changed; reports whether it was replaced
+	GetGeneration(key string) (int64, error)                                                                                     // Retrieve the generation counter identified by the key, zero when it is missing
+	BumpGeneration(key string, ttl time.Duration) error                                                                          // Increment the generation counter identified by the key, expiring after the TTL
+	SetMemberScore(key, member string, score float64) error                                                                      // Add or update a bare member of a sorted set, such as a group on a group leaderboard
+	RemoveMember(key, member string) error                                                                                       // Remove a bare member from a sorted set
+	GetMemberScores(key string, offset, limit int64) ([]MemberScore, error)                                                      // Retrieve a page of the bare members of a sorted set in descending score order
+	SetMarker(key string, ttl time.Duration) error                                                                               // Set a marker key that expires after the TTL, such as the negative cache entry of an unknown player
+	HasMarker(key string) (bool, error)                                                                                          // Report whether the marker key is set and has not expired
+	AcquireLock(key, token string, ttl time.Duration) (bool, error)                                                              // Take the lock identified by the key for the holder's token unless it is held, expiring after the TTL
+	RenewLock(key, token string, ttl time.Duration) (bool, error)                                                                // Push back the expiry of the lock to the TTL if it is still held with the holder's token, reporting whether it is
+	ReleaseLock(key, token string) error                                                                                         // Release the lock if it is still held with the holder's token
+	DeleteKey(key string) error                                                                                                  // Remove a leaderboard or marker from the cache
+	ExpireAt(key string, at time.Time) error                                                                                     // Make the leaderboard of a window period expire at the given time, accesses do not push it back
+	MemoryUsage() (int64, error)                                                                                                 // Report the number of bytes used by the cache
+	ColdestBoards(limit int64) ([]string, error)                                                                                 // Retrieve the IDs of the boards with cached leaderboards, least recently accessed first
+	EvictBoard(boardID string) error                                                                                             // Remove every cached leaderboard of the board, its group leaderboard included, except those being rebuilt, leaving them cold
+	DeleteKeysWithPrefix(prefix string) error                                                                                    // Remove every leaderboard and marker whose key starts with the prefix
+	DeleteKeysMatching(pattern string) error                                                                                     // Remove every leaderboard and marker whose key matches the glob pattern, such as the group leaderboards of every board
+	Connect()                                                                                                                    // Establish a connection to the cache
+	Close()                                                                                                                      // Close the cache connection
 }
@@ -0,0 +1,122 @@
+// Package cachekey is the single definition of the cache key schema: the key prefixes, the per-board namespaces
+// and the field names of the player HASH. Every cache key is built here, so changing the layout of the cache means
+// bumping Version and teaching the migrate-cache command to rewrite the previous layout.
+package cachekey
+
+import (
+	"quiz/internals/domain/window"
+	"strings"
+)
+
+// Version is the version of the key schema. Every key of this version starts with Root.
+const Version = 2
+
+const (
+	Root       = "quiz:v2:"            // Prefix of every key of the current schema version
+	VersionKey = "quiz:schema_version" // Key holding the schema version the cache was last migrated to
+
+	OutboxRelayLock = Root + "outbox_relay_lock" // Lock held by the instance relaying the outbox to the cache
+	EvictionLock    = Root + "eviction_lock"     // Lock held by the instance evicting cold boards from the cache
+	ScoreStreamLock = Root + "score_stream_lock" // Lock held by the instance following the score stream
+	BoardAccess     = Root + "board_access"      // ZSET of the boards whose leaderboards are cached, scored by the Unix time of their last access
+
+	PlayerPrefix = Root + "player:" // Prefix of the player HASHes
+)
+
+// GroupLeaderboardPattern is the SCAN pattern matching the group leaderboards of every board.
+var GroupLeaderboardPattern = GroupLeaderboard("*")
+
+// Field names of the player HASH. The HASH holds the details shared by every board, scores live in the leaderboards.
+const (
+	FieldPlayerID   = "id"   // ID of the player
+	FieldPlayerName = "name" // Name submitted with the player's latest score
+)
+
+// Board returns the namespace of the keys belonging to the board. The board ID is wrapped in a Redis Cluster hash tag,
+// so every leaderboard of a board, and the temporary keys derived from them, are stored in the same slot.
+func Board(boardID string) string {
+	return Root + "board:{" + boardID + "}:"
+}
+
+// Leaderboard returns the key of the leaderboard (ZSET) of a scope: a board, or one of its windows or attribute
+// leaderboards. Scopes derived from a board share its namespace, e.g. "quiz-1@daily:2026-10-16" is stored under
+// "quiz:v2:board:{quiz-1}:leaderboard@daily:2026-10-16". A scope ID prefix yields a key prefix.
+func Leaderboard(scopeID string) string {
+	boardID, scope := window.SplitScopeID(scopeID)
+	return Board(boardID) + "leaderboard" + scope
+}
+
+// BoardOf returns the ID of the board a key of a board namespace belongs to, and false for every other key.
+func BoardOf(key string) (string, bool) {
+	prefix := Root + "board:{"
+	if !strings.HasPrefix(key, prefix) {
+		return "", false
+	}
+	end := strings.Index(key, "}:")
+	if end < len(prefix) {
+		return "", false
+	}
+	return key[len(prefix):end], true
+}
+
+// ScopeOf returns the scope ID of a leaderboard key, see Leaderboard, and false for every other key of a board.
+func ScopeOf(key string) (string, bool) {
+	boardID, ok := BoardOf(key)
+	if !ok {
+		return "", false
+	}
+	scope, ok := strings.CutPrefix(key, Board(boardID)+"leaderboard")
+	if !ok || (scope != "" && !strings.HasPrefix(scope, window.ScopePrefix(""))) {
+		return "", false
+	}
+	return boardID + scope, true
+}
+
+// IsRebuildTemp reports whether the key is the temporary key of a leaderboard being rebuilt, see RebuildTemp.
+func IsRebuildTemp(key string) bool {
+	return strings.Contains(key, ":rebuild:")
+}
+
+// RebuildTemp returns the temporary key a leaderboard of the scope is rebuilt under before it replaces the live one.
+// The token of the rebuild keeps concurrent rebuilds apart, and the key shares the slot of the leaderboard.
+func RebuildTemp(scopeID, token string) string {
+	return Leaderboard(scopeID) + ":rebuild:" + token
+}
+
+// MissingPlayer returns the key of the marker recording that the player has no score on the board,
+// the negative cache entry of single player reads.
+func MissingPlayer(boardID, playerID string) string {
+	return Board(boardID) + "missing:" + playerID
+}
+
+// EmptyLeaderboard returns the key of the marker recording that a rebuild found no scores in the scope, so reads of
+// the cold leaderboard go to the database instead of rebuilding it again.
+func EmptyLeaderboard(scopeID string) string {
+	boardID, scope := window.SplitScopeID(scopeID)
+	return Board(boardID) + "empty" + scope
+}
+
+// RebuildLock returns the key of the lock held by the instance rebuilding the cached leaderboard of a scope.
+func RebuildLock(scopeID string) string {
+	boardID, scope := window.SplitScopeID(scopeID)
+	return Board(boardID) + "rebuild_lock" + scope
+}
+
+// Generation returns the key of the counter bumped whenever the scores of the scope's board are reset or deleted.
+// Every scope of a board shares it. A rebuild only swaps in its leaderboard while the generation it started under is
+// current, so it never brings back scores removed while it ran.
+func Generation(scopeID string) string {
+	boardID, _ := window.SplitScopeID(scopeID)
+	return Board(boardID) + "generation"
+}
+
+// GroupLeaderboard returns the key of the group leaderboard (ZSET) of the board. It lives in the board's namespace,
+// so it expires and is evicted together with the board's leaderboards.
+func GroupLeaderboard(boardID string) string {
+	return Board(boardID) + "groups"
+}
+
+// Player returns the key of the player's HASH.
+func Player(playerID string) string {
+	return PlayerPrefix + playerID
+}
@@ -2,14 +2,26 @@ package repositories
 
 import (
 	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/window"
+	"time"
 )
 
 // IDBRepository defines the operations for interacting with the database,
 // specifically for managing player scores, including retrieval, insertion, and updates.
+// Every score belongs to a board, and scores of different boards never affect each other.
+// Methods taking a TieBreak order players with TieBreak.Before.
 type IDBRepository interface {
-	UpdateOrInsertPlayerScore(player player_score.PlayerScore) error // Insert a new player score or update an existing one in the database
-	GetTopPlayers() ([]player_score.PlayerScore, error)              // Retrieve the top players' scores from the database (in case of cache miss)
-	GetPlayerScore(playerID string) (int, error)                     // Retrieve a single player's score from the database by their ID
-	Connect()                                                        // Establish a connection to the database
-	Close()                                                          // Close the database connection
+	UpdateOrInsertPlayerScore(boardID string, up player_score.UpdatePolicy, player player_score.PlayerScore, windows []window.Scope) (player_score.ScoreChange, error) // Insert a new player score or update an existing one as allowed by the update policy on the board and the window periods at once, reporting the old and new score on the board
+	GetTopPlayers(boardID string, tb player_score.TieBreak, page player_score.PageRequest) ([]player_score.PlayerScore, error)                                         // Retrieve a page of the top players' scores from the database (in case of cache miss)
+	IncrementPlayerScore(boardID string, inc player_score.Increment, windows []window.Scope) (player_score.PlayerScore, player_score.ScoreChange, error)               // Atomically change a player's score on the board and the window periods by a delta, returning the stored player and the change on the board
+	DeleteScores(boardID string) error                                                                                                                                 // Remove every player score of the board, used to reset it
+	DeleteScoresWithPrefix(prefix string) error                                                                                                                        // Remove every player score of the scopes whose ID starts with the prefix
+	DeleteArchivedScores(boardID string, players []player_score.PlayerScore) error                                                                                     // Remove the given scores from the board and its attribute leaderboards where they are still stored unchanged
+	CountPlayers(boardID string) (int64, error)                                                                                                                        // Count the players that have a score on the board
+	CountHigherScores(boardID string, score int, distinct bool) (int64, error)                                                                                         // Count the players (or distinct scores) above the given score on the board
+	GetPlayerRank(boardID string, tb player_score.TieBreak, playerID string) (int64, int, error)                                                                       // Retrieve a player's position (starting at 1) and score on the board
+	GetPlayersChangedSince(boardID string, since time.Time) ([]player_score.PlayerScore, error)                                                                        // Retrieve the scores on the board reached at or after the given time
+	GetPlayerScores(boardID string, playerIDs []string) ([]player_score.PlayerScore, error)                                                                            // Retrieve the scores of the given players on the board, players without a score are left out
+	Connect()                                                                                                                                                          // Establish a connection to the database
+	Close()                                                                                                                                                            // Close the database connection
 }
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"errors"
+	"quiz/internals/domain/group"
+)
+
+var (
+	ErrGroupExists   = errors.New("group already exists") // Returned when creating a group whose ID is already taken
+	ErrGroupNotFound = errors.New("group not found")      // Returned when a group with the given ID does not exist
+)
+
+// IGroupRepository defines the operations for managing groups of players and their members in the database.
+type IGroupRepository interface {
+	CreateGroup(g group.Group) error                   // Create a new group, returns ErrGroupExists if the ID is taken
+	GetGroups() ([]group.Group, error)                 // Retrieve all groups
+	GetGroup(groupID string) (group.Group, error)      // Retrieve a single group by its ID, returns ErrGroupNotFound if missing
+	DeleteGroup(groupID string) error                  // Delete a group together with its memberships, returns ErrGroupNotFound if missing
+	AddGroupMember(groupID, playerID string) error     // Add a player to a group, adding an existing member is a no-op
+	RemoveGroupMember(groupID, playerID string) error  // Remove a player from a group, removing a non-member is a no-op
+	GetGroupMembers(groupID string) ([]string, error)  // Retrieve the IDs of the members of a group
+	GetPlayerGroups(playerID string) ([]string, error) // Retrieve the IDs of the groups a player belongs to
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"quiz/internals/domain/score_event"
+	"time"
+)
+
+// IHistoryRepository defines the operations for the append-only audit trail of score changes.
+type IHistoryRepository interface {
+	AppendScoreEvent(event score_event.ScoreEvent) error                                                         // Append a score change to the history
+	GetScoreHistory(boardID, playerID string, from, to time.Time, limit int64) ([]score_event.ScoreEvent, error) // Retrieve a player's score changes on the board between from and to (zero values are unbounded), newest first
+}
@@ -0,0 +1,523 @@
+package repositories
+
+import (
+	"log"
+	"math"
+	"path"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories/cachekey"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// MemoryCacheClient is an in-memory implementation of ICacheRepository.
+// It mirrors the behaviour of RedisClient (sorted sets keyed by name, player details
+// kept apart from the scores and redis.Nil for missing entries) and is safe for concurrent use.
+// Leaderboards and player details never expire, they only leave the cache when they are deleted or evicted.
+type MemoryCacheClient struct {
+	mu       sync.RWMutex                        // Guards sets, players and markers
+	sets     map[string]map[string]float64       // Sorted sets: key -> player ID -> sort value
+	players  map[string]player_score.PlayerScore // Player details, the equivalent of the player HASH
+	markers  map[string]marker                   // Marker and lock keys
+	accessMu sync.Mutex                          // Guards access, taken after mu when both are needed
+	access   map[string]time.Time                // Last access of the boards with cached leaderboards, by board ID
+}
+
+// marker is an expiring string key, such as a negative cache entry or a lock holding its holder's token.
+type marker struct {
+	value     string    // Value of the key, the token of a lock
+	expiresAt time.Time // Time the key expires at
+}
+
+// NewMemoryCacheClient creates a new, empty instance of MemoryCacheClient.
+func NewMemoryCacheClient() *MemoryCacheClient {
+	return &MemoryCacheClient{
+		sets:    make(map[string]map[string]float64),
+		players: make(map[string]player_score.PlayerScore),
+		markers: make(map[string]marker),
+		access:  make(map[string]time.Time),
+	}
+}
+
+// UpdatePlayerCache updates both the leaderboard and the player's details in the cache.
+// Under the keep_best and keep_lowest update policies the entry is only replaced by a better score, like ZADD GT/LT.
+// A cold leaderboard is left cold, only the player's details are updated under the keep_latest policy.
+func (mc *MemoryCacheClient) UpdatePlayerCache(key string, tb player_score.TieBreak, up player_score.UpdatePolicy, playerScore player_score.PlayerScore) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if _, warm := mc.sets[key]; !warm {
+		if up.OrDefault() == player_score.UpdateKeepLatest {
+			mc.players[playerScore.PlayerID] = playerScore
+		}
+		return nil
+	}
+
+	value := tb.SortValue(playerScore)
+	if stored, ok := mc.sets[key][playerScore.PlayerID]; ok {
+		if (up == player_score.UpdateKeepBest && value <= stored) || (up == player_score.UpdateKeepLowest && value >= stored) {
+			return nil
+		}
+	}
+
+	mc.zadd(key, playerScore.PlayerID, value)
+	mc.players[playerScore.PlayerID] = playerScore
+	return nil
+}
+
+// GetSetByKey returns a page of the sorted set identified by the key in descending score order,
+// joined with the player details. A missing key yields an empty result, as in Redis.
+func (mc *MemoryCacheClient) GetSetByKey(key string, tb player_score.TieBreak, page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	mc.touch(key)
+	members := mc.sortedMembers(key)
+	playerScores := make([]player_score.PlayerScore, len(members))
+	for i, member := range members {
+		playerScores[i] = tb.FromSortValue(member, mc.sets[key][member]) // Restore the score and tie breaking field
+	}
+	playerScores = page.Slice(tb, playerScores)
+
+	// Join the page with the player details, entries without details are returned without a name
+	for i := range playerScores {
+		playerScores[i].PlayerName = mc.playerName(playerScores[i].PlayerID)
+	}
+
+	return playerScores, nil
+}
+
+// GetSetMembers returns the entries of the given players in the sorted set identified by the key in descending score order,
+// joined with the player details. Players missing from the set are left out, as with ZINTERSTORE.
+func (mc *MemoryCacheClient) GetSetMembers(key string, tb player_score.TieBreak, playerIDs []string) ([]player_score.PlayerScore, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	mc.touch(key)
+	wanted := make(map[string]bool, len(playerIDs))
+	for _, playerID := range playerIDs {
+		wanted[playerID] = true
+	}
+
+	playerScores := []player_score.PlayerScore{}
+	for _, member := range mc.sortedMembers(key) {
+		if !wanted[member] {
+			continue
+		}
+		playerScore := tb.FromSortValue(member, mc.sets[key][member]) // Restore the score and tie breaking field
+		playerScore.PlayerName = mc.playerName(member)
+		playerScores = append(playerScores, playerScore)
+	}
+
+	return playerScores, nil
+}
+
+// GetSetSize counts the members of the sorted set identified by the key.
+func (mc *MemoryCacheClient) GetSetSize(key string) (int64, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	return int64(len(mc.sets[key])), nil
+}
+
+// FillPlayerCache adds the player's score to the sorted set and the player's details, keeping an entry that is
+// already cached, like ZADD NX and HSETNX. A cold leaderboard is left cold, the player is then not cached at all.
+func (mc *MemoryCacheClient) FillPlayerCache(key string, tb player_score.TieBreak, playerScore player_score.PlayerScore) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if _, warm := mc.sets[key]; !warm {
+		return nil
+	}
+
+	if _, ok := mc.sets[key][playerScore.PlayerID]; !ok {
+		mc.zadd(key, playerScore.PlayerID, tb.SortValue(playerScore))
+	}
+	if _, ok := mc.players[playerScore.PlayerID]; !ok {
+		mc.players[playerScore.PlayerID] = playerScore
+	}
+	return nil
+}
+
+// GetRecordByKey retrieves a player's entry in the sorted set identified by the key, joined with the player details.
+// It returns redis.Nil if the player is not a member of the set, missing details only leave the name empty.
+func (mc *MemoryCacheClient) GetRecordByKey(key string, tb player_score.TieBreak, playerID string) (player_score.PlayerScore, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	mc.touch(key)
+	value, ok := mc.sets[key][playerID]
+	if !ok {
+		return player_score.PlayerScore{}, redis.Nil
+	}
+
+	playerScore := tb.FromSortValue(playerID, value)
+	playerScore.PlayerName = mc.playerName(playerID)
+	return playerScore, nil
+}
+
+// GetRank retrieves the position (starting at 1) and score of a player in the sorted set identified by the key.
+// It returns redis.Nil if the player is not a member of the set.
+func (mc *MemoryCacheClient) GetRank(key, playerID string) (int64, int, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	mc.touch(key)
+	set := mc.sets[key]
+	value, ok := set[playerID]
+	if !ok {
+		return 0, 0, redis.Nil
+	}
+
+	rank := int64(1)
+	for otherID, otherValue := range set {
+		if otherValue > value || (otherValue == value && otherID > playerID) {
+			rank++
+		}
+	}
+	return rank, int(math.Floor(value)), nil
+}
+
+// AddToSet adds the players' sort values to the sorted set identified by the key and stores their details.
+func (mc *MemoryCacheClient) AddToSet(key string, tb player_score.TieBreak, players []player_score.PlayerScore) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.touch(key)
+	for _, player := range players {
+		mc.zadd(key, player.PlayerID, tb.SortValue(player))
+		mc.players[player.PlayerID] = player_score.PlayerScore{PlayerID: player.PlayerID, PlayerName: player.PlayerName}
+	}
+	return nil
+}
+
+// ReplaceSet replaces the sorted set identified by the key with the one stored under the temporary key,
+// or removes it when the temporary key holds no set. When the generation counter no longer holds the generation,
+// the temporary set is dropped instead and false is returned.
+func (mc *MemoryCacheClient) ReplaceSet(tempKey, key, generationKey string, generation int64) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if mc.generation(generationKey) != generation {
+		delete(mc.sets, tempKey)
+		return false, nil
+	}
+
+	if set, ok := mc.sets[tempKey]; ok {
+		mc.sets[key] = set
+		delete(mc.sets, tempKey)
+	} else {
+		delete(mc.sets, key)
+	}
+	return true, nil
+}
+
+// GetGeneration reads the generation counter identified by the key, a missing or expired key is generation zero.
+func (mc *MemoryCacheClient) GetGeneration(key string) (int64, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	return mc.generation(key), nil
+}
+
+// BumpGeneration increments the generation counter identified by the key and sets its expiry, like INCR and EXPIRE.
+func (mc *MemoryCacheClient) BumpGeneration(key string, ttl time.Duration) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.markers[key] = marker{value: strconv.FormatInt(mc.generation(key)+1, 10), expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// generation returns the generation counter stored as a marker under the key. The caller must hold the write lock.
+func (mc *MemoryCacheClient) generation(key string) int64 {
+	m, _ := mc.liveMarker(key)
+	generation, _ := strconv.ParseInt(m.value, 10, 64)
+	return generation
+}
+
+// SetMarker sets the marker key, expiring after the TTL.
+func (mc *MemoryCacheClient) SetMarker(key string, ttl time.Duration) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.markers[key] = marker{expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// HasMarker reports whether the marker key is set and has not expired. Expired markers are removed lazily.
+func (mc *MemoryCacheClient) HasMarker(key string) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	_, ok := mc.liveMarker(key)
+	return ok, nil
+}
+
+// AcquireLock takes the lock identified by the key for the token unless it is held and has not expired, like SET NX PX.
+func (mc *MemoryCacheClient) AcquireLock(key, token string, ttl time.Duration) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if _, held := mc.liveMarker(key); held {
+		return false, nil
+	}
+	mc.markers[key] = marker{value: token, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// RenewLock pushes back the expiry of the lock identified by the key to the TTL if it is still held with the token.
+func (mc *MemoryCacheClient) RenewLock(key, token string, ttl time.Duration) (bool, error) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	m, held := mc.liveMarker(key)
+	if !held || m.value != token {
+		return false, nil
+	}
+	mc.markers[key] = marker{value: token, expiresAt: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// ReleaseLock releases the lock identified by the key if it is still held with the token.
+func (mc *MemoryCacheClient) ReleaseLock(key, token string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if m, held := mc.liveMarker(key); held && m.value == token {
+		delete(mc.markers, key)
+	}
+	return nil
+}
+
+// liveMarker returns the marker stored under the key unless it has expired, removing expired markers.
+// The caller must hold the write lock.
+func (mc *MemoryCacheClient) liveMarker(key string) (marker, bool) {
+	m, ok := mc.markers[key]
+	if ok && !time.Now().Before(m.expiresAt) {
+		delete(mc.markers, key)
+		return marker{}, false
+	}
+	return m, ok
+}
+
+// DeleteKey removes the leaderboard or marker identified by the key, player details are kept.
+func (mc *MemoryCacheClient) DeleteKey(key string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	delete(mc.sets, key)
+	delete(mc.markers, key)
+	return nil
+}
+
+// SetMemberScore adds or updates a bare member of the sorted set identified by the key, without any player details.
+func (mc *MemoryCacheClient) SetMemberScore(key, member string, score float64) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	mc.zadd(key, member, score)
+	return nil
+}
+
+// RemoveMember removes a bare member from the sorted set identified by the key.
+// A set left empty is removed, as Redis does, so the leaderboard turns cold.
+func (mc *MemoryCacheClient) RemoveMember(key, member string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	delete(mc.sets[key], member)
+	if set, ok := mc.sets[key]; ok && len(set) == 0 {
+		delete(mc.sets, key)
+	}
+	return nil
+}
+
+// GetMemberScores retrieves a page of the bare members of the sorted set identified by the key in descending score order.
+// A limit of zero or less returns every member after the offset.
+func (mc *MemoryCacheClient) GetMemberScores(key string, offset, limit int64) ([]MemberScore, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	mc.touch(key)
+	members := mc.sortedMembers(key)
+	if offset > int64(len(members)) {
+		offset = int64(len(members))
+	}
+	members = members[offset:]
+	if limit > 0 && limit < int64(len(members)) {
+		members = members[:limit]
+	}
+
+	scores := make([]MemberScore, len(members))
+	for i, member := range members {
+		scores[i] = MemberScore{Member: member, Score: mc.sets[key][member]}
+	}
+	return scores, nil
+}
+
+// ExpireAt does nothing, leaderboards never expire in memory.
+func (mc *MemoryCacheClient) ExpireAt(key string, at time.Time) error {
+	return nil
+}
+
+// DeleteKeysWithPrefix removes every leaderboard and marker whose key starts with the prefix, player details are kept.
+func (mc *MemoryCacheClient) DeleteKeysWithPrefix(prefix string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for key := range mc.sets {
+		if strings.HasPrefix(key, prefix) {
+			delete(mc.sets, key)
+		}
+	}
+	for key := range mc.markers {
+		if strings.HasPrefix(key, prefix) {
+			delete(mc.markers, key)
+		}
+	}
+	return nil
+}
+
+// DeleteKeysMatching removes every leaderboard and marker whose key matches the glob pattern, player details are kept.
+// The pattern is matched with path.Match, which supports the patterns of the key schema.
+func (mc *MemoryCacheClient) DeleteKeysMatching(pattern string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	for key := range mc.sets {
+		if matched, _ := path.Match(pattern, key); matched {
+			delete(mc.sets, key)
+		}
+	}
+	for key := range mc.markers {
+		if matched, _ := path.Match(pattern, key); matched {
+			delete(mc.markers, key)
+		}
+	}
+	return nil
+}
+
+// MemoryUsage returns a rough estimate of the bytes held by the cache: the keys, the members and their sort values,
+// and the player details.
+func (mc *MemoryCacheClient) MemoryUsage() (int64, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	var usage int64
+	for key, set := range mc.sets {
+		usage += int64(len(key))
+		for member := range set {
+			usage += int64(len(member)) + 8
+		}
+	}
+	for playerID, player := range mc.players {
+		usage += int64(len(playerID) + len(player.PlayerName))
+	}
+	return usage, nil
+}
+
+// ColdestBoards returns the IDs of the boards whose leaderboards were accessed, least recently accessed first.
+func (mc *MemoryCacheClient) ColdestBoards(limit int64) ([]string, error) {
+	mc.accessMu.Lock()
+	defer mc.accessMu.Unlock()
+
+	boardIDs := make([]string, 0, len(mc.access))
+	for boardID := range mc.access {
+		boardIDs = append(boardIDs, boardID)
+	}
+	sort.Slice(boardIDs, func(i, j int) bool {
+		a, b := mc.access[boardIDs[i]], mc.access[boardIDs[j]]
+		return a.Before(b) || (a.Equal(b) && boardIDs[i] < boardIDs[j])
+	})
+
+	if limit > 0 && int64(len(boardIDs)) > limit {
+		boardIDs = boardIDs[:limit]
+	}
+	return boardIDs, nil
+}
+
+// EvictBoard deletes every leaderboard of the board, its group leaderboard included, except those being rebuilt,
+// and forgets the board's last access.
+func (mc *MemoryCacheClient) EvictBoard(boardID string) error {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	prefix := cachekey.Leaderboard(boardID)
+	for key := range mc.sets {
+		if strings.HasPrefix(key, prefix) && !cachekey.IsRebuildTemp(key) {
+			delete(mc.sets, key)
+		}
+	}
+	delete(mc.sets, cachekey.GroupLeaderboard(boardID))
+
+	mc.accessMu.Lock()
+	delete(mc.access, boardID)
+	mc.accessMu.Unlock()
+	return nil
+}
+
+// touch records the access of the board the leaderboard identified by the key belongs to. It is called by reads and
+// rebuilds only, so writes to a cold or evicted board never bring it back into the access ranking. The caller holds mu.
+func (mc *MemoryCacheClient) touch(key string) {
+	boardID, ok := cachekey.BoardOf(key)
+	if !ok {
+		return
+	}
+
+	mc.accessMu.Lock()
+	mc.access[boardID] = time.Now()
+	mc.accessMu.Unlock()
+}
+
+// Connect is a no-op for the in-memory cache, it only logs that the store is ready.
+func (mc *MemoryCacheClient) Connect() {
+	log.Println("Using in-memory cache!")
+}
+
+// Close is a no-op for the in-memory cache.
+func (mc *MemoryCacheClient) Close() {}
+
+// playerName returns the cached name of the player, or an empty name when the player's details are missing,
+// like a pipelined HGET on a missing HASH. The caller must hold the lock.
+func (mc *MemoryCacheClient) playerName(playerID string) string {
+	player, ok := mc.players[playerID]
+	if !ok {
+		log.Println("Player details missing from memory cache, returning the entry without a name:", playerID)
+	}
+	return player.PlayerName
+}
+
+// sortedMembers returns the members of the sorted set identified by the key in ZREVRANGE order:
+// highest value first, equal values in reverse lexicographical order. The caller must hold the read lock.
+func (mc *MemoryCacheClient) sortedMembers(key string) []string {
+	set := mc.sets[key]
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if set[members[i]] != set[members[j]] {
+			return set[members[i]] > set[members[j]]
+		}
+		return members[i] > members[j]
+	})
+	return members
+}
+
+// zadd adds or updates a member of the sorted set identified by the key.
+// The caller must hold the write lock.
+func (mc *MemoryCacheClient) zadd(key, playerID string, score float64) {
+	set, ok := mc.sets[key]
+	if !ok {
+		set = make(map[string]float64)
+		mc.sets[key] = set
+	}
+	set[playerID] = score
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"fmt"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories/cachekey"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// parityStep is one score write applied to both the database and the cache, the way the service applies it.
+type parityStep struct {
+	player    player_score.PlayerScore // Submitted score, used when increment is nil
+	increment *player_score.Increment  // Increment to apply instead of a submitted score
+}
+
+// parityEntry is what a leaderboard entry looks like to a reader under the tie break policy.
+type parityEntry struct {
+	PlayerID string
+	Name     string
+	Score    int
+	Tie      int64 // Value of the policy's tie breaking field
+}
+
+// parityEntries converts leaderboard entries into what a reader sees of them under the policy.
+func parityEntries(tb player_score.TieBreak, players []player_score.PlayerScore) []parityEntry {
+	entries := make([]parityEntry, len(players))
+	for i, player := range players {
+		entries[i] = parityEntry{PlayerID: player.PlayerID, Name: player.PlayerName, Score: player.Score}
+		switch tb.Field() {
+		case "achieved_at":
+			entries[i].Tie = player.AchievedAt.Unix()
+		case "completion_ms":
+			entries[i].Tie = player.CompletionTime
+		}
+	}
+	return entries
+}
+
+// paritySteps returns a sequence of writes with ties, replaced and kept scores, renames and bounded increments.
+func paritySteps() []parityStep {
+	base := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+	bound := func(v int) *int { return &v }
+
+	return []parityStep{
+		{player: player_score.PlayerScore{PlayerID: "p1", PlayerName: "Ada", Score: 40, AchievedAt: at(1), CompletionTime: 900}},
+		{player: player_score.PlayerScore{PlayerID: "p2", PlayerName: "Bob", Score: 40, AchievedAt: at(2), CompletionTime: 800}},
+		{player: player_score.PlayerScore{PlayerID: "p3", PlayerName: "Cy", Score: 25, AchievedAt: at(3), CompletionTime: 700}},
+		{player: player_score.PlayerScore{PlayerID: "p4", PlayerName: "Di", Score: 60, AchievedAt: at(4), CompletionTime: 600}},
+		// The cache is rebuilt from the database here, the remaining steps update both
+		{player: player_score.PlayerScore{PlayerID: "p5", PlayerName: "Eve", Score: 40, AchievedAt: at(5), CompletionTime: 500}},
+		{player: player_score.PlayerScore{PlayerID: "p1", PlayerName: "Ada L.", Score: 55, AchievedAt: at(6), CompletionTime: 400}},
+		{player: player_score.PlayerScore{PlayerID: "p4", PlayerName: "Di", Score: 20, AchievedAt: at(7), CompletionTime: 300}},
+		{increment: &player_score.Increment{PlayerID: "p3", Delta: 15, AchievedAt: at(8)}},
+		{increment: &player_score.Increment{PlayerID: "p6", PlayerName: "Fay", Delta: 70, Ceiling: bound(50), AchievedAt: at(9)}},
+		{increment: &player_score.Increment{PlayerID: "p2", Delta: -100, Floor: bound(0), AchievedAt: at(10)}},
+		{player: player_score.PlayerScore{PlayerID: "p7", PlayerName: "Gus", Score: -5, AchievedAt: at(11), CompletionTime: 100}},
+		{player: player_score.PlayerScore{PlayerID: "p5", PlayerName: "Eve", Score: 40, AchievedAt: at(12), CompletionTime: 50}},
+	}
+}
+
+func TestMemoryCacheMatchesMemoryDB(t *testing.T) {
+	const boardID = "quiz"
+	key := cachekey.Leaderboard(boardID)
+	tieBreaks := []player_score.TieBreak{player_score.TieBreakEarliest, player_score.TieBreakFastest, player_score.TieBreakDense, player_score.TieBreakStandard}
+	policies := []player_score.UpdatePolicy{player_score.UpdateKeepLatest, player_score.UpdateKeepBest, player_score.UpdateKeepLowest}
+
+	for _, tb := range tieBreaks {
+		for _, up := range policies {
+			t.Run(fmt.Sprintf("%s/%s", tb, up), func(t *testing.T) {
+				db, cache := NewMemoryDBClient(), NewMemoryCacheClient()
+
+				for i, step := range paritySteps() {
+					if i == 4 {
+						// Warm the cache the way a rebuild does
+						players, err := db.GetTopPlayers(boardID, tb, player_score.PageRequest{})
+						if err != nil {
+							t.Fatalf("GetTopPlayers error = %v", err)
+						}
+						if err := cache.AddToSet(key, tb, players); err != nil {
+							t.Fatalf("AddToSet error = %v", err)
+						}
+					}
+
+					if step.increment != nil {
+						player, _, err := db.IncrementPlayerScore(boardID, *step.increment, nil)
+						if err != nil {
+							t.Fatalf("IncrementPlayerScore error = %v", err)
+						}
+						if err := cache.UpdatePlayerCache(key, tb, player_score.UpdateKeepLatest, player); err != nil {
+							t.Fatalf("UpdatePlayerCache error = %v", err)
+						}
+						continue
+					}
+
+					change, err := db.UpdateOrInsertPlayerScore(boardID, up, step.player, nil)
+					if err != nil {
+						t.Fatalf("UpdateOrInsertPlayerScore error = %v", err)
+					}
+					if change.Replaced {
+						if err := cache.UpdatePlayerCache(key, tb, up, step.player); err != nil {
+							t.Fatalf("UpdatePlayerCache error = %v", err)
+						}
+					}
+				}
+
+				assertParity(t, db, cache, boardID, key, tb)
+			})
+		}
+	}
+}
+
+// assertParity compares every read of the leaderboard between the database and the cache.
+func assertParity(t *testing.T, db *MemoryDBClient, cache *MemoryCacheClient, boardID, key string, tb player_score.TieBreak) {
+	t.Helper()
+
+	dbCount, _ := db.CountPlayers(boardID)
+	cacheCount, _ := cache.GetSetSize(key)
+	if dbCount != cacheCount {
+		t.Fatalf("CountPlayers = %d, GetSetSize = %d", dbCount, cacheCount)
+	}
+
+	all, _ := db.GetTopPlayers(boardID, tb, player_score.PageRequest{})
+	cursor := player_score.CursorOf(all[2])
+	pages := []player_score.PageRequest{
+		{},
+		{Limit: 3},
+		{Offset: 2, Limit: 3},
+		{Offset: int64(len(all)) - 1, Limit: 3},
+		{Limit: 3, After: &cursor},
+	}
+	for _, page := range pages {
+		dbPage, err := db.GetTopPlayers(boardID, tb, page)
+		if err != nil {
+			t.Fatalf("GetTopPlayers(%+v) error = %v", page, err)
+		}
+		cachePage, err := cache.GetSetByKey(key, tb, page)
+		if err != nil {
+			t.Fatalf("GetSetByKey(%+v) error = %v", page, err)
+		}
+		if got, want := parityEntries(tb, cachePage), parityEntries(tb, dbPage); !reflect.DeepEqual(got, want) {
+			t.Errorf("page %+v: cache = %+v, database = %+v", page, got, want)
+		}
+	}
+
+	for _, player := range all {
+		dbPosition, dbScore, err := db.GetPlayerRank(boardID, tb, player.PlayerID)
+		if err != nil {
+			t.Fatalf("GetPlayerRank(%q) error = %v", player.PlayerID, err)
+		}
+		cachePosition, cacheScore, err := cache.GetRank(key, player.PlayerID)
+		if err != nil {
+			t.Fatalf("GetRank(%q) error = %v", player.PlayerID, err)
+		}
+		if dbPosition != cachePosition || dbScore != cacheScore {
+			t.Errorf("player %q: cache position %d score %d, database position %d score %d", player.PlayerID, cachePosition, cacheScore, dbPosition, dbScore)
+		}
+
+		record, err := cache.GetRecordByKey(key, tb, player.PlayerID)
+		if err != nil {
+			t.Fatalf("GetRecordByKey(%q) error = %v", player.PlayerID, err)
+		}
+		if got, want := parityEntries(tb, []player_score.PlayerScore{record}), parityEntries(tb, []player_score.PlayerScore{player}); !reflect.DeepEqual(got, want) {
+			t.Errorf("record %q: cache = %+v, database = %+v", player.PlayerID, got, want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"log"
+	"quiz/internals/domain/player_score"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeStreamHistoryLost is the MongoDB error code returned when a change stream is resumed from a token
+// that fell off the oplog.
+const changeStreamHistoryLost = 286
+
+// scoreDocument is a score document as stored in the scores collection.
+type scoreDocument struct {
+	BoardID                  string `bson:"board_id"` // Scope the score belongs to
+	player_score.PlayerScore `bson:",inline"`
+}
+
+// scoreChangeEvent is the part of a change stream event of the scores collection read by WatchScores.
+type scoreChangeEvent struct {
+	OperationType            string         `bson:"operationType"`            // insert, update, replace or delete
+	FullDocument             *scoreDocument `bson:"fullDocument"`             // Document after the change, nil for deletes and for documents deleted since
+	FullDocumentBeforeChange *scoreDocument `bson:"fullDocumentBeforeChange"` // Document before the change, only set for deletes
+}
+
+// resumeTokens returns the collection holding the resume tokens of the change streams followed by the service.
+func (mdb *MongoDBClient) resumeTokens() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("resume_tokens")
+}
+
+// WatchScores follows the change stream of the scores collection after the resume token, or from now without one,
+// and calls handle for every insert, update, replace and delete. checkpoint is called with the resume token after the
+// last event of every batch returned by the server, and after every poll that returned no event: the post-batch token
+// keeps moving while the collection is quiet, so a stored token does not fall off the oplog on an idle stream.
+// Deletes only carry the deleted score when the collection records pre-images, which WatchScores enables on
+// MongoDB 6.0 and newer; deletes without one are skipped. It returns ErrResumeTokenLost when the token fell off the oplog.
+func (mdb *MongoDBClient) WatchScores(ctx context.Context, resumeToken []byte, handle func(change ScoreDocumentChange) error, checkpoint func(resumeToken []byte) error) error {
+	enablePreImages := bson.D{{Key: "collMod", Value: mdb.scores().Name()}, {Key: "changeStreamPreAndPostImages", Value: bson.M{"enabled": true}}}
+	if err := mdb.scores().Database().RunCommand(ctx, enablePreImages).Err(); err != nil {
+		log.Println("Failed to enable pre-images on the MongoDB scores collection, deletes will be skipped:", err)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}}}
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if resumeToken != nil {
+		opts.SetStartAfter(bson.Raw(resumeToken))
+	}
+
+	stream, err := mdb.scores().Watch(ctx, pipeline, opts)
+	if err != nil {
+		return watchError(err)
+	}
+	defer stream.Close(mdb.Ctx)
+
+	for {
+		if !stream.TryNext(ctx) {
+			if err := stream.Err(); err != nil {
+				return watchError(err)
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := checkpoint(stream.ResumeToken()); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var event scoreChangeEvent
+		if err := stream.Decode(&event); err != nil {
+			log.Println("Failed to decode MongoDB change event:", err)
+			return err
+		}
+
+		document, deleted := event.FullDocument, event.OperationType == "delete"
+		if deleted {
+			document = event.FullDocumentBeforeChange
+		}
+		if document == nil {
+			log.Println("Skipping MongoDB change event without a document:", event.OperationType)
+		} else if err := handle(ScoreDocumentChange{ScopeID: document.BoardID, Player: document.PlayerScore, Deleted: deleted}); err != nil {
+			return err
+		}
+
+		if stream.RemainingBatchLength() == 0 {
+			if err := checkpoint(stream.ResumeToken()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// watchError translates the error of a change stream, reporting a token that fell off the oplog as ErrResumeTokenLost.
+func watchError(err error) error {
+	var commandErr mongo.CommandError
+	if errors.As(err, &commandErr) && commandErr.Code == changeStreamHistoryLost {
+		return ErrResumeTokenLost
+	}
+	if err != nil {
+		log.Println("Failed to watch MongoDB scores:", err)
+	}
+	return err
+}
+
+// GetResumeToken retrieves the stored resume token of the stream, nil if there is none.
+func (mdb *MongoDBClient) GetResumeToken(stream string) ([]byte, error) {
+	var stored struct {
+		Token bson.Raw `bson:"token"`
+	}
+	err := mdb.resumeTokens().FindOne(mdb.Ctx, bson.M{"_id": stream}).Decode(&stored)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		log.Println("Failed to get resume token from MongoDB:", err)
+		return nil, err
+	}
+	return stored.Token, nil
+}
+
+// SaveResumeToken stores the resume token of the stream, a nil token removes it.
+func (mdb *MongoDBClient) SaveResumeToken(stream string, token []byte) error {
+	var err error
+	if token == nil {
+		_, err = mdb.resumeTokens().DeleteOne(mdb.Ctx, bson.M{"_id": stream})
+	} else {
+		_, err = mdb.resumeTokens().UpdateByID(mdb.Ctx, stream,
+			bson.M{"$set": bson.M{"token": bson.Raw(token), "updated_at": time.Now().UTC()}},
+			options.Update().SetUpsert(true))
+	}
+	if err != nil {
+		log.Println("Failed to save resume token to MongoDB:", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScoreMigration reports the player scores rewritten by MigrateLegacyScores.
+type ScoreMigration struct {
+	DryRun     bool   `json:"dry_run"`    // Whether the scores were only counted, without being rewritten
+	BoardID    string `json:"board_id"`   // Board the legacy scores were moved to
+	Moved      int64  `json:"moved"`      // Legacy scores assigned to the board
+	Superseded int64  `json:"superseded"` // Legacy scores dropped because the player already had a score on the board
+}
+
+// MigrateLegacyScores assigns the player scores written before boards were introduced, which carry no board_id and are
+// therefore invisible to every board, to the given board. A legacy score is dropped when the player already has a
+// score on the board, since that one was written by the current version of the service. The migration is idempotent
+// and may run while the service is serving requests. With dryRun set the scores are only counted.
+func (mdb *MongoDBClient) MigrateLegacyScores(boardID string, dryRun bool) (ScoreMigration, error) {
+	report := ScoreMigration{DryRun: dryRun, BoardID: boardID}
+
+	legacy := bson.M{"board_id": bson.M{"$exists": false}}
+	cursor, err := mdb.scores().Find(mdb.Ctx, legacy, options.Find().SetProjection(bson.M{"_id": 1, "player_id": 1}))
+	if err != nil {
+		log.Println("Failed to find legacy player scores in MongoDB:", err)
+		return report, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	for cursor.Next(mdb.Ctx) {
+		var doc struct {
+			ID       interface{} `bson:"_id"`
+			PlayerID string      `bson:"player_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			log.Println("Failed to decode legacy player score:", err)
+			return report, err
+		}
+
+		if dryRun {
+			n, err := mdb.scores().CountDocuments(mdb.Ctx, bson.M{"board_id": boardID, "player_id": doc.PlayerID})
+			if err != nil {
+				log.Println("Failed to count player scores in MongoDB:", err)
+				return report, err
+			}
+			if n > 0 {
+				report.Superseded++
+			} else {
+				report.Moved++
+			}
+			continue
+		}
+
+		_, err := mdb.scores().UpdateOne(mdb.Ctx, bson.M{"_id": doc.ID, "board_id": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"board_id": boardID}})
+		if mongo.IsDuplicateKeyError(err) {
+			if _, err := mdb.scores().DeleteOne(mdb.Ctx, bson.M{"_id": doc.ID, "board_id": bson.M{"$exists": false}}); err != nil {
+				log.Println("Failed to delete superseded legacy player score from MongoDB:", err)
+				return report, err
+			}
+			report.Superseded++
+			continue
+		}
+		if err != nil {
+			log.Println("Failed to migrate legacy player score in MongoDB:", err)
+			return report, err
+		}
+		report.Moved++
+	}
+	if err := cursor.Err(); err != nil {
+		log.Println("Failed to iterate legacy player scores:", err)
+		return report, err
+	}
+	return report, nil
+}
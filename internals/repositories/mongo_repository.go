@@ -2,10 +2,24 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"log"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/group"
+	"quiz/internals/domain/outbox"
 	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/profile"
+	"quiz/internals/domain/score_event"
+	"quiz/internals/domain/season"
+	"quiz/internals/domain/snapshot"
+	"quiz/internals/domain/social"
+	"quiz/internals/domain/standing"
+	"quiz/internals/domain/window"
+	"regexp"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -22,54 +36,1272 @@ func NewMongoDBClient(ctx context.Context, uri string) *MongoDBClient {
 	return &MongoDBClient{Ctx: ctx, URI: uri}
 }
 
-// UpdateOrInsertPlayerScore updates a player's score if it exists, or inserts it if it doesn't.
-func (mdb *MongoDBClient) UpdateOrInsertPlayerScore(player player_score.PlayerScore) error {
-	collection := mdb.Client.Database("game").Collection("players")
-	_, err := collection.UpdateOne(
+// scores returns the collection holding the player scores of every board.
+func (mdb *MongoDBClient) scores() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("players")
+}
+
+// history returns the append-only collection holding every score change.
+func (mdb *MongoDBClient) history() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("score_events")
+}
+
+// boards returns the collection holding the leaderboard definitions.
+func (mdb *MongoDBClient) boards() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("boards")
+}
+
+// seasons returns the collection holding the seasons of every board.
+func (mdb *MongoDBClient) seasons() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("seasons")
+}
+
+// standings returns the collection holding the entries of every archived leaderboard.
+func (mdb *MongoDBClient) standings() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("standings")
+}
+
+// snapshots returns the collection holding the named snapshots of every board.
+func (mdb *MongoDBClient) snapshots() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("snapshots")
+}
+
+// groups returns the collection holding the groups of players.
+func (mdb *MongoDBClient) groups() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("groups")
+}
+
+// groupMembers returns the collection holding one document per membership of a player in a group.
+func (mdb *MongoDBClient) groupMembers() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("group_members")
+}
+
+// follows returns the collection holding one document per follow edge of the social graph.
+func (mdb *MongoDBClient) follows() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("follows")
+}
+
+// profiles returns the collection holding the player profiles.
+func (mdb *MongoDBClient) profiles() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("profiles")
+}
+
+// outbox returns the collection holding the outbox entries of score changes not yet applied to the cache.
+func (mdb *MongoDBClient) outbox() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("outbox")
+}
+
+// playerVersions returns the collection of the per-player versions bumped by score writes and profile changes.
+func (mdb *MongoDBClient) playerVersions() *mongo.Collection {
+	return mdb.Client.Database("game").Collection("player_versions")
+}
+
+// withOutbox runs write in a transaction and appends an outbox entry for the player's score in every scope write
+// reports as changed, so the entries are stored if and only if the changes are. The transaction may be retried,
+// in which case write runs again.
+func (mdb *MongoDBClient) withOutbox(playerID string, write func(ctx mongo.SessionContext) ([]string, error)) error {
+	session, err := mdb.Client.StartSession()
+	if err != nil {
+		log.Println("Failed to start MongoDB session:", err)
+		return err
+	}
+	defer session.EndSession(mdb.Ctx)
+
+	_, err = session.WithTransaction(mdb.Ctx, func(ctx mongo.SessionContext) (interface{}, error) {
+		changed, err := write(ctx)
+		if err != nil || len(changed) == 0 {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+		entries := make([]interface{}, len(changed))
+		for i, scopeID := range changed {
+			entry := outbox.NewEntry(scopeID, playerID, now)
+			entry.ID = primitive.NewObjectID().Hex() // Hex ObjectIDs sort in the order they were created
+			entries[i] = entry
+		}
+		_, err = mdb.outbox().InsertMany(ctx, entries)
+		return nil, err
+	})
+	return err
+}
+
+// UpdateOrInsertPlayerScore updates a player's score on the board if it exists and the update policy allows it,
+// or inserts it if it doesn't, and does the same for the scopes of the given window periods, each under its own
+// policy check. A replaced score of the board is copied to the attribute leaderboards of the player's profile.
+// The keep_best and keep_lowest policies are enforced by an update pipeline that keeps the stored fields
+// unless the submitted score is higher (or lower), so concurrent submissions cannot lose the best one.
+// The previous score is read atomically with the update, so the returned change of the board is exact even under
+// concurrent writes. Every write and the outbox entries of the replaced scores are stored in one transaction.
+func (mdb *MongoDBClient) UpdateOrInsertPlayerScore(boardID string, up player_score.UpdatePolicy, player player_score.PlayerScore, windows []window.Scope) (player_score.ScoreChange, error) {
+	var change player_score.ScoreChange
+	err := mdb.withOutbox(player.PlayerID, func(ctx mongo.SessionContext) ([]string, error) {
+		if err := mdb.lockPlayer(ctx, player.PlayerID); err != nil {
+			return nil, err
+		}
+
+		var changed []string
+		var err error
+		if change, err = mdb.storeScore(ctx, boardID, up, player, time.Time{}); err != nil {
+			return nil, err
+		}
+		if change.Replaced {
+			copies, err := mdb.copyAttributeScores(ctx, boardID, player)
+			if err != nil {
+				return nil, err
+			}
+			changed = append(append(changed, boardID), copies...)
+		}
+
+		for _, w := range windows {
+			windowChange, err := mdb.storeScore(ctx, w.ID, up, player, w.ExpiresAt)
+			if err != nil {
+				return nil, err
+			}
+			if windowChange.Replaced {
+				changed = append(changed, w.ID)
+			}
+		}
+		return changed, nil
+	})
+	if err != nil {
+		log.Println("Failed to update player score in MongoDB:", err)
+		return player_score.ScoreChange{}, err
+	}
+	return change, nil
+}
+
+// storeScore writes the player's score to the scope as allowed by the update policy within the transaction of ctx and
+// returns the change. A non-zero expiresAt is stored with the score, so the TTL index removes it once it passed.
+func (mdb *MongoDBClient) storeScore(ctx mongo.SessionContext, scopeID string, up player_score.UpdatePolicy, player player_score.PlayerScore, expiresAt time.Time) (player_score.ScoreChange, error) {
+	fields := bson.M{ // Update player score, name and tie breaking fields
+		"score":         player.Score,
+		"player_name":   player.PlayerName,
+		"achieved_at":   player.AchievedAt,
+		"completion_ms": player.CompletionTime,
+	}
+
+	var update interface{} = bson.M{"$set": fields}
+	if comparison := map[player_score.UpdatePolicy]string{player_score.UpdateKeepBest: "$gt", player_score.UpdateKeepLowest: "$lt"}[up.OrDefault()]; comparison != "" {
+		replaces := bson.M{"$or": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$type": "$score"}, "missing"}}, // New players always take the submitted score
+			bson.M{comparison: bson.A{player.Score, "$score"}},
+		}}
+		conditional := bson.M{}
+		for field, value := range fields {
+			conditional[field] = bson.M{"$cond": bson.A{replaces, bson.M{"$literal": value}, "$" + field}}
+		}
+		if !expiresAt.IsZero() {
+			conditional["expires_at"] = bson.M{"$literal": expiresAt}
+		}
+		update = mongo.Pipeline{{{Key: "$set", Value: conditional}}}
+	} else if !expiresAt.IsZero() {
+		fields["expires_at"] = expiresAt
+	}
+
+	var previous player_score.PlayerScore
+	err := mdb.scores().FindOneAndUpdate(
+		ctx,
+		bson.M{"board_id": scopeID, "player_id": player.PlayerID}, // Filter by scope and player ID
+		update,
+		options.FindOneAndUpdate().
+			SetUpsert(true).                   // Insert new document if none exists
+			SetReturnDocument(options.Before). // Return the document as it was before the update
+			SetProjection(bson.M{"score": 1}),
+	).Decode(&previous)
+
+	change := player_score.ScoreChange{OldScore: previous.Score, NewScore: player.Score, Replaced: true}
+	if err == mongo.ErrNoDocuments {
+		change.Created, err = true, nil
+	}
+	if err != nil {
+		return player_score.ScoreChange{}, err
+	}
+
+	if !change.Created && !up.Replaces(previous.Score, player.Score) {
+		change.NewScore, change.Replaced = previous.Score, false
+	}
+	return change, nil
+}
+
+// IncrementPlayerScore atomically changes a player's score on the board and in the scopes of the given window periods
+// by the increment's delta, inserting the player with a score of zero first where needed, and copies the resulting
+// score of the board to the attribute leaderboards of the player's profile. Unbounded increments use
+// $inc, bounded ones use an update pipeline clamping the result between the floor and the ceiling. The bounds apply to
+// the score of every scope on its own, so a window period's score stays between them too. A scope whose score the
+// bounds keep as it was is left untouched, keeping the time its score was reached, and gets no outbox entry. The
+// previous document of the board is read atomically with the update, so the returned player and change are exact even
+// under concurrent writes. Every write and the outbox entries of the changes are stored in one transaction.
+func (mdb *MongoDBClient) IncrementPlayerScore(boardID string, inc player_score.Increment, windows []window.Scope) (player_score.PlayerScore, player_score.ScoreChange, error) {
+	var previous, player player_score.PlayerScore
+	var created, replaced bool
+	err := mdb.withOutbox(inc.PlayerID, func(ctx mongo.SessionContext) ([]string, error) {
+		if err := mdb.lockPlayer(ctx, inc.PlayerID); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if previous, created, err = mdb.incrementScore(ctx, boardID, inc, time.Time{}); err != nil {
+			return nil, err
+		}
+
+		var changed []string
+		player, replaced = previous, created || inc.Changes(previous.Score)
+		player.PlayerID = inc.PlayerID
+		if replaced {
+			player.Score = inc.Apply(previous.Score)
+			player.AchievedAt = inc.AchievedAt
+			if inc.PlayerName != "" {
+				player.PlayerName = inc.PlayerName
+			}
+			copies, err := mdb.copyAttributeScores(ctx, boardID, player)
+			if err != nil {
+				return nil, err
+			}
+			changed = append(append(changed, boardID), copies...)
+		}
+
+		for _, w := range windows {
+			before, windowCreated, err := mdb.incrementScore(ctx, w.ID, inc, w.ExpiresAt)
+			if err != nil {
+				return nil, err
+			}
+			if windowCreated || inc.Changes(before.Score) {
+				changed = append(changed, w.ID)
+			}
+		}
+		return changed, nil
+	})
+	if err != nil {
+		log.Println("Failed to increment player score in MongoDB:", err)
+		return player_score.PlayerScore{}, player_score.ScoreChange{}, err
+	}
+	return player, player_score.ScoreChange{OldScore: previous.Score, NewScore: player.Score, Created: created, Replaced: replaced}, nil
+}
+
+// incrementScore changes the player's score in the scope by the increment's delta within the transaction of ctx and
+// returns the document as it was before, and whether it was created. A non-zero expiresAt is stored with the score,
+// so the TTL index removes it once it passed. The fields of a bounded increment are only written when the clamped
+// score differs from the stored one.
+func (mdb *MongoDBClient) incrementScore(ctx mongo.SessionContext, scopeID string, inc player_score.Increment, expiresAt time.Time) (player_score.PlayerScore, bool, error) {
+	set := bson.M{"achieved_at": inc.AchievedAt}
+	if inc.PlayerName != "" {
+		set["player_name"] = inc.PlayerName
+	}
+	if !expiresAt.IsZero() {
+		set["expires_at"] = expiresAt
+	}
+
+	var update interface{}
+	if !inc.Bounded() {
+		update = bson.M{"$inc": bson.M{"score": inc.Delta}, "$set": set}
+	} else {
+		score := bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$score", 0}}, inc.Delta}}
+		if inc.Floor != nil {
+			score = bson.M{"$max": bson.A{score, *inc.Floor}}
+		}
+		if inc.Ceiling != nil {
+			score = bson.M{"$min": bson.A{score, *inc.Ceiling}}
+		}
+		changes := bson.M{"$or": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$type": "$score"}, "missing"}}, // New players always get the clamped score
+			bson.M{"$ne": bson.A{score, "$score"}},
+		}}
+		conditional := bson.M{"score": score}
+		for field, value := range set {
+			// Names such as "$score" must not be evaluated as expressions
+			conditional[field] = bson.M{"$cond": bson.A{changes, bson.M{"$literal": value}, "$" + field}}
+		}
+		update = mongo.Pipeline{{{Key: "$set", Value: conditional}}}
+	}
+
+	var previous player_score.PlayerScore
+	err := mdb.scores().FindOneAndUpdate(
+		ctx,
+		bson.M{"board_id": scopeID, "player_id": inc.PlayerID}, // Filter by scope and player ID
+		update,
+		options.FindOneAndUpdate().
+			SetUpsert(true).                   // Insert new document if none exists
+			SetReturnDocument(options.Before), // Return the document as it was before the update
+	).Decode(&previous)
+
+	if err == mongo.ErrNoDocuments {
+		return player_score.PlayerScore{}, true, nil
+	}
+	if err != nil {
+		return player_score.PlayerScore{}, false, err
+	}
+	return previous, false, nil
+}
+
+// lockPlayer bumps the version of the player within the transaction of ctx. Score writes and profile changes of the
+// player both bump it, so concurrent ones conflict and MongoDB retries one of them after the other committed. A score
+// is therefore never copied to the attribute leaderboards of a profile that is being changed.
+func (mdb *MongoDBClient) lockPlayer(ctx mongo.SessionContext, playerID string) error {
+	_, err := mdb.playerVersions().UpdateOne(ctx, bson.M{"_id": playerID}, bson.M{"$inc": bson.M{"version": 1}}, options.Update().SetUpsert(true))
+	return err
+}
+
+// copyAttributeScores copies the player's score on the board to the attribute leaderboards of the player's profile,
+// read within the transaction of ctx, and returns the IDs of the scopes written. Players without a profile are on no
+// attribute leaderboard.
+func (mdb *MongoDBClient) copyAttributeScores(ctx mongo.SessionContext, boardID string, player player_score.PlayerScore) ([]string, error) {
+	var p profile.Profile
+	err := mdb.profiles().FindOne(ctx, bson.M{"_id": player.PlayerID}).Decode(&p)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var scopeIDs []string
+	for _, a := range profile.Attributes {
+		if value := p.Value(a); value != "" {
+			scopeID := profile.ScopeID(boardID, a, value)
+			if _, err := mdb.storeScore(ctx, scopeID, player_score.UpdateKeepLatest, player, time.Time{}); err != nil {
+				return nil, err
+			}
+			scopeIDs = append(scopeIDs, scopeID)
+		}
+	}
+	return scopeIDs, nil
+}
+
+// moveAttributeScores moves the player's scores on every board from the attribute leaderboards of the old profile to
+// those of the new one within the transaction of ctx, for every attribute whose value changed, and returns the IDs of
+// the scopes written.
+func (mdb *MongoDBClient) moveAttributeScores(ctx mongo.SessionContext, before, after profile.Profile) ([]string, error) {
+	var attributes []profile.Attribute
+	for _, a := range profile.Attributes {
+		if before.Value(a) != after.Value(a) {
+			attributes = append(attributes, a)
+		}
+	}
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := mdb.scores().Find(ctx, bson.M{"player_id": after.PlayerID})
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		BoardID                  string `bson:"board_id"`
+		player_score.PlayerScore `bson:",inline"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	var scopeIDs []string
+	for _, doc := range docs {
+		if boardID, scope := window.SplitScopeID(doc.BoardID); boardID == "" || scope != "" {
+			continue // Only the scores of the boards themselves are copied
+		}
+
+		for _, a := range attributes {
+			if from := before.Value(a); from != "" {
+				scopeID := profile.ScopeID(doc.BoardID, a, from)
+				if _, err := mdb.scores().DeleteOne(ctx, bson.M{"board_id": scopeID, "player_id": after.PlayerID}); err != nil {
+					return nil, err
+				}
+				scopeIDs = append(scopeIDs, scopeID)
+			}
+			if to := after.Value(a); to != "" {
+				scopeID := profile.ScopeID(doc.BoardID, a, to)
+				if _, err := mdb.storeScore(ctx, scopeID, player_score.UpdateKeepLatest, doc.PlayerScore, time.Time{}); err != nil {
+					return nil, err
+				}
+				scopeIDs = append(scopeIDs, scopeID)
+			}
+		}
+	}
+	return scopeIDs, nil
+}
+
+// AppendScoreEvent appends a score change to the history collection.
+func (mdb *MongoDBClient) AppendScoreEvent(event score_event.ScoreEvent) error {
+	if _, err := mdb.history().InsertOne(mdb.Ctx, event); err != nil {
+		log.Println("Failed to append score event in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetScoreHistory retrieves a player's score changes on the board, newest first.
+// Zero from and to values leave the time range open on that side, a limit of zero or less returns every event.
+func (mdb *MongoDBClient) GetScoreHistory(boardID, playerID string, from, to time.Time, limit int64) ([]score_event.ScoreEvent, error) {
+	filter := bson.M{"board_id": boardID, "player_id": playerID}
+	timestamp := bson.M{}
+	if !from.IsZero() {
+		timestamp["$gte"] = from
+	}
+	if !to.IsZero() {
+		timestamp["$lte"] = to
+	}
+	if len(timestamp) > 0 {
+		filter["timestamp"] = timestamp
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := mdb.history().Find(mdb.Ctx, filter, opts)
+	if err != nil {
+		log.Println("Failed to get score history from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	events := []score_event.ScoreEvent{}
+	if err := cursor.All(mdb.Ctx, &events); err != nil {
+		log.Println("Failed to decode score events:", err)
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetTopPlayers retrieves a page of the top players of the board sorted by score in descending order.
+// Equal scores are ordered by the tie breaking field of the policy and then by player ID in descending order,
+// the same way Redis orders a ZSET scored with TieBreak.SortValue, which also makes a Cursor usable for keyset paging.
+func (mdb *MongoDBClient) GetTopPlayers(boardID string, tb player_score.TieBreak, page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+	filter := bson.M{"board_id": boardID}
+	opts := options.Find().SetSort(sortOrder(tb)) // Sort by score (highest first)
+	if page.After != nil {
+		// Continue right after the cursor
+		filter["$or"] = orderFilter(tb, page.After.Player(), false)
+	} else if page.Offset > 0 {
+		opts.SetSkip(page.Offset)
+	}
+	if page.Limit > 0 {
+		opts.SetLimit(page.Limit)
+	}
+
+	cursor, err := mdb.scores().Find(mdb.Ctx, filter, opts)
+	if err != nil {
+		log.Println("Failed to get top players from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	topPlayers := []player_score.PlayerScore{}
+	for cursor.Next(mdb.Ctx) {
+		var player player_score.PlayerScore
+		if err := cursor.Decode(&player); err != nil {
+			log.Println("Failed to decode player data:", err)
+			return nil, err
+		}
+		topPlayers = append(topPlayers, player)
+	}
+
+	return topPlayers, nil
+}
+
+// DeleteScores removes every player score of the board.
+func (mdb *MongoDBClient) DeleteScores(boardID string) error {
+	if _, err := mdb.scores().DeleteMany(mdb.Ctx, bson.M{"board_id": boardID}); err != nil {
+		log.Println("Failed to delete player scores from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteScoresWithPrefix removes every player score of the scopes whose ID starts with the prefix.
+func (mdb *MongoDBClient) DeleteScoresWithPrefix(prefix string) error {
+	filter := bson.M{"board_id": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	if _, err := mdb.scores().DeleteMany(mdb.Ctx, filter); err != nil {
+		log.Println("Failed to delete player scores from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteArchivedScores removes the given scores from the board and from its attribute leaderboards, matching every
+// score by player, score and achievement time, so scores changed since they were read are kept.
+func (mdb *MongoDBClient) DeleteArchivedScores(boardID string, players []player_score.PlayerScore) error {
+	if len(players) == 0 {
+		return nil
+	}
+
+	scopes := bson.A{bson.M{"board_id": boardID}}
+	for _, a := range profile.Attributes {
+		scopes = append(scopes, bson.M{"board_id": bson.M{"$regex": "^" + regexp.QuoteMeta(profile.ScopePrefix(boardID, a))}})
+	}
+	archived := make(bson.A, len(players))
+	for i, player := range players {
+		match := bson.M{"player_id": player.PlayerID, "score": player.Score, "achieved_at": player.AchievedAt}
+		if player.AchievedAt.IsZero() {
+			match["achieved_at"] = bson.M{"$in": bson.A{nil, player.AchievedAt}} // Scores written without a time have no field
+		}
+		archived[i] = match
+	}
+
+	filter := bson.M{"$and": bson.A{bson.M{"$or": scopes}, bson.M{"$or": archived}}}
+	if _, err := mdb.scores().DeleteMany(mdb.Ctx, filter); err != nil {
+		log.Println("Failed to delete archived player scores from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// CountPlayers counts the players that have a score on the board.
+func (mdb *MongoDBClient) CountPlayers(boardID string) (int64, error) {
+	count, err := mdb.scores().CountDocuments(mdb.Ctx, bson.M{"board_id": boardID})
+	if err != nil {
+		log.Println("Failed to count players in MongoDB:", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountHigherScores counts the players with a score above the given one on the board.
+// When distinct is true it counts the distinct scores above it instead, which is what dense ranking needs.
+func (mdb *MongoDBClient) CountHigherScores(boardID string, score int, distinct bool) (int64, error) {
+	filter := bson.M{"board_id": boardID, "score": bson.M{"$gt": score}}
+	if !distinct {
+		count, err := mdb.scores().CountDocuments(mdb.Ctx, filter)
+		if err != nil {
+			log.Println("Failed to count higher scores in MongoDB:", err)
+			return 0, err
+		}
+		return count, nil
+	}
+
+	cursor, err := mdb.scores().Aggregate(mdb.Ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": "$score"}}},
+		{{Key: "$count", Value: "count"}},
+	})
+	if err != nil {
+		log.Println("Failed to count distinct higher scores in MongoDB:", err)
+		return 0, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	var result struct {
+		Count int64 `bson:"count"`
+	}
+	if cursor.Next(mdb.Ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, err
+		}
+	}
+	return result.Count, cursor.Err()
+}
+
+// GetPlayerRank retrieves the position (starting at 1) and score of a player on the board.
+// The position is one more than the number of players ordered before the player by the tie break policy.
+func (mdb *MongoDBClient) GetPlayerRank(boardID string, tb player_score.TieBreak, playerID string) (int64, int, error) {
+	var player player_score.PlayerScore
+	err := mdb.scores().FindOne(mdb.Ctx, bson.M{"board_id": boardID, "player_id": playerID}).Decode(&player)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	higher, err := mdb.scores().CountDocuments(mdb.Ctx, bson.M{
+		"board_id": boardID,
+		"$or":      orderFilter(tb, player, true),
+	})
+	if err != nil {
+		log.Println("Failed to count higher scores in MongoDB:", err)
+		return 0, 0, err
+	}
+	return higher + 1, player.Score, nil
+}
+
+// GetPlayerScores retrieves the scores of the given players on the board, players without a score are left out.
+func (mdb *MongoDBClient) GetPlayerScores(boardID string, playerIDs []string) ([]player_score.PlayerScore, error) {
+	players := []player_score.PlayerScore{}
+	if len(playerIDs) == 0 {
+		return players, nil
+	}
+
+	cursor, err := mdb.scores().Find(mdb.Ctx, bson.M{"board_id": boardID, "player_id": bson.M{"$in": playerIDs}})
+	if err != nil {
+		log.Println("Failed to get player scores from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	if err := cursor.All(mdb.Ctx, &players); err != nil {
+		log.Println("Failed to decode player data:", err)
+		return nil, err
+	}
+	return players, nil
+}
+
+// GetPlayersChangedSince retrieves the scores on the board whose achievement time is at or after the given time.
+// Every write and increment of the service sets the achievement time, so these are the scores changed since then.
+func (mdb *MongoDBClient) GetPlayersChangedSince(boardID string, since time.Time) ([]player_score.PlayerScore, error) {
+	cursor, err := mdb.scores().Find(mdb.Ctx, bson.M{"board_id": boardID, "achieved_at": bson.M{"$gte": since}})
+	if err != nil {
+		log.Println("Failed to get changed player scores from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	players := []player_score.PlayerScore{}
+	if err := cursor.All(mdb.Ctx, &players); err != nil {
+		log.Println("Failed to decode player data:", err)
+		return nil, err
+	}
+	return players, nil
+}
+
+// CreateBoard stores a new board, it returns ErrBoardExists if a board with the same ID already exists.
+func (mdb *MongoDBClient) CreateBoard(b board.Board) error {
+	_, err := mdb.boards().InsertOne(mdb.Ctx, b)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrBoardExists
+	}
+	if err != nil {
+		log.Println("Failed to create board in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetBoards retrieves all boards sorted by their ID.
+func (mdb *MongoDBClient) GetBoards() ([]board.Board, error) {
+	cursor, err := mdb.boards().Find(mdb.Ctx, bson.D{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		log.Println("Failed to get boards from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	boards := []board.Board{}
+	if err := cursor.All(mdb.Ctx, &boards); err != nil {
+		log.Println("Failed to decode board data:", err)
+		return nil, err
+	}
+	return boards, nil
+}
+
+// GetBoard retrieves a single board by its ID, it returns ErrBoardNotFound if the board does not exist.
+func (mdb *MongoDBClient) GetBoard(boardID string) (board.Board, error) {
+	var result board.Board
+	err := mdb.boards().FindOne(mdb.Ctx, bson.M{"_id": boardID}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return board.Board{}, ErrBoardNotFound
+	}
+	return result, err
+}
+
+// DeleteBoard removes the board and every score recorded on it, including its score history.
+func (mdb *MongoDBClient) DeleteBoard(boardID string) error {
+	result, err := mdb.boards().DeleteOne(mdb.Ctx, bson.M{"_id": boardID})
+	if err != nil {
+		log.Println("Failed to delete board from MongoDB:", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrBoardNotFound
+	}
+
+	// Scores of the board's windows live under scope IDs starting with the board ID
+	scopes := bson.M{"$regex": "^" + regexp.QuoteMeta(window.ScopePrefix(boardID))}
+	if _, err := mdb.scores().DeleteMany(mdb.Ctx, bson.M{"$or": bson.A{bson.M{"board_id": boardID}, bson.M{"board_id": scopes}}}); err != nil {
+		log.Println("Failed to delete board scores from MongoDB:", err)
+		return err
+	}
+
+	if _, err := mdb.seasons().DeleteMany(mdb.Ctx, bson.M{"board_id": boardID}); err != nil {
+		log.Println("Failed to delete board seasons from MongoDB:", err)
+		return err
+	}
+
+	if _, err := mdb.snapshots().DeleteMany(mdb.Ctx, bson.M{"board_id": boardID}); err != nil {
+		log.Println("Failed to delete board snapshots from MongoDB:", err)
+		return err
+	}
+
+	if _, err := mdb.standings().DeleteMany(mdb.Ctx, bson.M{"board_id": boardID}); err != nil {
+		log.Println("Failed to delete board standings from MongoDB:", err)
+		return err
+	}
+
+	if _, err := mdb.history().DeleteMany(mdb.Ctx, bson.M{"board_id": boardID}); err != nil {
+		log.Println("Failed to delete board score history from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// CreateSeason stores a new active season. A unique index on the seasons of a board rejects duplicate IDs
+// and a partial unique index on the active seasons rejects a second active season on the same board.
+func (mdb *MongoDBClient) CreateSeason(s season.Season) error {
+	_, err := mdb.seasons().InsertOne(mdb.Ctx, s)
+	if mongo.IsDuplicateKeyError(err) {
+		if _, err := mdb.GetSeason(s.BoardID, s.ID); err == nil {
+			return ErrSeasonExists
+		}
+		return ErrSeasonActive
+	}
+	if err != nil {
+		log.Println("Failed to create season in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetSeasons retrieves every season of the board, newest first.
+func (mdb *MongoDBClient) GetSeasons(boardID string) ([]season.Season, error) {
+	cursor, err := mdb.seasons().Find(mdb.Ctx, bson.M{"board_id": boardID}, options.Find().SetSort(bson.M{"started_at": -1}))
+	if err != nil {
+		log.Println("Failed to get seasons from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	seasons := []season.Season{}
+	if err := cursor.All(mdb.Ctx, &seasons); err != nil {
+		log.Println("Failed to decode season data:", err)
+		return nil, err
+	}
+	return seasons, nil
+}
+
+// GetSeason retrieves a single season of the board, it returns ErrSeasonNotFound if the season does not exist.
+func (mdb *MongoDBClient) GetSeason(boardID, seasonID string) (season.Season, error) {
+	var result season.Season
+	err := mdb.seasons().FindOne(mdb.Ctx, bson.M{"board_id": boardID, "season_id": seasonID}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return season.Season{}, ErrSeasonNotFound
+	}
+	return result, err
+}
+
+// EndSeason marks the active season as ended, it returns ErrSeasonNotFound if the season is not active.
+func (mdb *MongoDBClient) EndSeason(boardID, seasonID string, endedAt time.Time, total int64) error {
+	result, err := mdb.seasons().UpdateOne(
+		mdb.Ctx,
+		bson.M{"board_id": boardID, "season_id": seasonID, "active": true},
+		bson.M{"$set": bson.M{"active": false, "ended_at": endedAt, "total": total}},
+	)
+	if err != nil {
+		log.Println("Failed to end season in MongoDB:", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrSeasonNotFound
+	}
+	return nil
+}
+
+// CreateSnapshot stores a new snapshot, it returns ErrSnapshotExists if the name is already taken on the board.
+func (mdb *MongoDBClient) CreateSnapshot(s snapshot.Snapshot) error {
+	_, err := mdb.snapshots().InsertOne(mdb.Ctx, s)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSnapshotExists
+	}
+	if err != nil {
+		log.Println("Failed to create snapshot in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// CompleteSnapshot marks a snapshot as complete with its number of players.
+func (mdb *MongoDBClient) CompleteSnapshot(boardID, name string, total int64) error {
+	result, err := mdb.snapshots().UpdateOne(
 		mdb.Ctx,
-		bson.M{"player_id": player.PlayerID}, // Filter by player ID
-		bson.M{"$set": bson.M{"score": player.Score, "player_name": player.PlayerName}}, // Update player score and name
-		options.Update().SetUpsert(true),                                                // Insert new document if none exists
+		bson.M{"board_id": boardID, "name": name},
+		bson.M{"$set": bson.M{"complete": true, "total": total}},
 	)
 	if err != nil {
-		log.Println("Failed to update player score in MongoDB:", err)
+		log.Println("Failed to complete snapshot in MongoDB:", err)
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return ErrSnapshotNotFound
+	}
 	return nil
 }
 
-// GetTopPlayers retrieves the top players sorted by score in descending order.
-func (mdb *MongoDBClient) GetTopPlayers() ([]player_score.PlayerScore, error) {
-	collection := mdb.Client.Database("game").Collection("players")
-	cursor, err := collection.Find(mdb.Ctx, bson.D{}, options.Find().SetSort(bson.M{"score": -1})) // Sort by score (highest first)
+// GetSnapshots retrieves every snapshot of the board, newest first.
+func (mdb *MongoDBClient) GetSnapshots(boardID string) ([]snapshot.Snapshot, error) {
+	cursor, err := mdb.snapshots().Find(mdb.Ctx, bson.M{"board_id": boardID}, options.Find().SetSort(bson.M{"taken_at": -1}))
 	if err != nil {
-		log.Println("Failed to get top players from MongoDB:", err)
+		log.Println("Failed to get snapshots from MongoDB:", err)
 		return nil, err
 	}
 	defer cursor.Close(mdb.Ctx)
 
-	var topPlayers []player_score.PlayerScore
-	for cursor.Next(mdb.Ctx) {
-		var player player_score.PlayerScore
-		if err := cursor.Decode(&player); err != nil {
-			log.Println("Failed to decode player data:", err)
+	snapshots := []snapshot.Snapshot{}
+	if err := cursor.All(mdb.Ctx, &snapshots); err != nil {
+		log.Println("Failed to decode snapshot data:", err)
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetSnapshot retrieves a single snapshot of the board, it returns ErrSnapshotNotFound if the snapshot does not exist.
+func (mdb *MongoDBClient) GetSnapshot(boardID, name string) (snapshot.Snapshot, error) {
+	var result snapshot.Snapshot
+	err := mdb.snapshots().FindOne(mdb.Ctx, bson.M{"board_id": boardID, "name": name}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return snapshot.Snapshot{}, ErrSnapshotNotFound
+	}
+	return result, err
+}
+
+// DeleteSnapshot removes a snapshot of the board, it returns ErrSnapshotNotFound if the snapshot does not exist.
+func (mdb *MongoDBClient) DeleteSnapshot(boardID, name string) error {
+	result, err := mdb.snapshots().DeleteOne(mdb.Ctx, bson.M{"board_id": boardID, "name": name})
+	if err != nil {
+		log.Println("Failed to delete snapshot from MongoDB:", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrSnapshotNotFound
+	}
+	return nil
+}
+
+// CreateGroup stores a new group, it returns ErrGroupExists if a group with the same ID already exists.
+func (mdb *MongoDBClient) CreateGroup(g group.Group) error {
+	_, err := mdb.groups().InsertOne(mdb.Ctx, g)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrGroupExists
+	}
+	if err != nil {
+		log.Println("Failed to create group in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetGroups retrieves all groups sorted by their ID.
+func (mdb *MongoDBClient) GetGroups() ([]group.Group, error) {
+	cursor, err := mdb.groups().Find(mdb.Ctx, bson.D{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		log.Println("Failed to get groups from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	groups := []group.Group{}
+	if err := cursor.All(mdb.Ctx, &groups); err != nil {
+		log.Println("Failed to decode group data:", err)
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetGroup retrieves a single group by its ID, it returns ErrGroupNotFound if the group does not exist.
+func (mdb *MongoDBClient) GetGroup(groupID string) (group.Group, error) {
+	var result group.Group
+	err := mdb.groups().FindOne(mdb.Ctx, bson.M{"_id": groupID}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return group.Group{}, ErrGroupNotFound
+	}
+	return result, err
+}
+
+// DeleteGroup removes the group and every membership in it.
+func (mdb *MongoDBClient) DeleteGroup(groupID string) error {
+	result, err := mdb.groups().DeleteOne(mdb.Ctx, bson.M{"_id": groupID})
+	if err != nil {
+		log.Println("Failed to delete group from MongoDB:", err)
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return ErrGroupNotFound
+	}
+
+	if _, err := mdb.groupMembers().DeleteMany(mdb.Ctx, bson.M{"group_id": groupID}); err != nil {
+		log.Println("Failed to delete group members from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// AddGroupMember adds a player to a group, adding an existing member is a no-op.
+func (mdb *MongoDBClient) AddGroupMember(groupID, playerID string) error {
+	membership := bson.M{"group_id": groupID, "player_id": playerID}
+	_, err := mdb.groupMembers().UpdateOne(mdb.Ctx, membership, bson.M{"$setOnInsert": membership}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Println("Failed to add group member in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveGroupMember removes a player from a group, removing a non-member is a no-op.
+func (mdb *MongoDBClient) RemoveGroupMember(groupID, playerID string) error {
+	if _, err := mdb.groupMembers().DeleteOne(mdb.Ctx, bson.M{"group_id": groupID, "player_id": playerID}); err != nil {
+		log.Println("Failed to remove group member from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetGroupMembers retrieves the IDs of the members of a group, sorted by player ID.
+func (mdb *MongoDBClient) GetGroupMembers(groupID string) ([]string, error) {
+	return mdb.distinctIDs(mdb.groupMembers(), bson.M{"group_id": groupID}, "player_id")
+}
+
+// GetPlayerGroups retrieves the IDs of the groups a player belongs to, sorted by group ID.
+func (mdb *MongoDBClient) GetPlayerGroups(playerID string) ([]string, error) {
+	return mdb.distinctIDs(mdb.groupMembers(), bson.M{"player_id": playerID}, "group_id")
+}
+
+// Follow stores a follow edge, following a player twice is a no-op.
+func (mdb *MongoDBClient) Follow(f social.Follow) error {
+	edge := bson.M{"follower_id": f.FollowerID, "followee_id": f.FolloweeID}
+	_, err := mdb.follows().UpdateOne(mdb.Ctx, edge, bson.M{"$setOnInsert": bson.M{"created_at": f.CreatedAt}}, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Println("Failed to store follow edge in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// Unfollow removes a follow edge, removing a missing edge is a no-op.
+func (mdb *MongoDBClient) Unfollow(followerID, followeeID string) error {
+	if _, err := mdb.follows().DeleteOne(mdb.Ctx, bson.M{"follower_id": followerID, "followee_id": followeeID}); err != nil {
+		log.Println("Failed to remove follow edge from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetFollowing retrieves the IDs of the players the player follows, sorted by player ID.
+func (mdb *MongoDBClient) GetFollowing(playerID string) ([]string, error) {
+	return mdb.distinctIDs(mdb.follows(), bson.M{"follower_id": playerID}, "followee_id")
+}
+
+// GetFollowers retrieves the IDs of the players following the player, sorted by player ID.
+func (mdb *MongoDBClient) GetFollowers(playerID string) ([]string, error) {
+	return mdb.distinctIDs(mdb.follows(), bson.M{"followee_id": playerID}, "follower_id")
+}
+
+// distinctIDs returns the given field of the documents of the collection matching the filter, sorted by that field.
+// It reads the memberships of groups and the edges of the social graph, whose documents hold a pair of IDs.
+func (mdb *MongoDBClient) distinctIDs(collection *mongo.Collection, filter bson.M, field string) ([]string, error) {
+	cursor, err := collection.Find(mdb.Ctx, filter, options.Find().SetSort(bson.M{field: 1}).SetProjection(bson.M{field: 1}))
+	if err != nil {
+		log.Println("Failed to get IDs from MongoDB collection", collection.Name(), err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	var documents []bson.M
+	if err := cursor.All(mdb.Ctx, &documents); err != nil {
+		log.Println("Failed to decode ID data from MongoDB collection", collection.Name(), err)
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(documents))
+	for _, document := range documents {
+		if id, ok := document[field].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// CreateProfile stores a new profile, it returns ErrProfileExists if the player already has one.
+// The player's scores are copied to the attribute leaderboards of the profile in the same transaction.
+func (mdb *MongoDBClient) CreateProfile(p profile.Profile) error {
+	err := mdb.withOutbox(p.PlayerID, func(ctx mongo.SessionContext) ([]string, error) {
+		if err := mdb.lockPlayer(ctx, p.PlayerID); err != nil {
 			return nil, err
 		}
-		topPlayers = append(topPlayers, player)
+		if _, err := mdb.profiles().InsertOne(ctx, p); err != nil {
+			return nil, err
+		}
+		return mdb.moveAttributeScores(ctx, profile.Profile{PlayerID: p.PlayerID}, p)
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrProfileExists
+	}
+	if err != nil {
+		log.Println("Failed to create profile in MongoDB:", err)
+		return err
 	}
+	return nil
+}
 
-	return topPlayers, nil
+// GetProfile retrieves the profile of a player, it returns ErrProfileNotFound if the player has none.
+func (mdb *MongoDBClient) GetProfile(playerID string) (profile.Profile, error) {
+	var result profile.Profile
+	err := mdb.profiles().FindOne(mdb.Ctx, bson.M{"_id": playerID}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return profile.Profile{}, ErrProfileNotFound
+	}
+	return result, err
+}
+
+// GetProfiles retrieves the profiles of the given players in a single query, players without a profile are left out.
+func (mdb *MongoDBClient) GetProfiles(playerIDs []string) ([]profile.Profile, error) {
+	profiles := []profile.Profile{}
+	if len(playerIDs) == 0 {
+		return profiles, nil
+	}
+
+	cursor, err := mdb.profiles().Find(mdb.Ctx, bson.M{"_id": bson.M{"$in": playerIDs}})
+	if err != nil {
+		log.Println("Failed to get profiles from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	if err := cursor.All(mdb.Ctx, &profiles); err != nil {
+		log.Println("Failed to decode profile data:", err)
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// UpdateProfile applies the update to the profile of a player and returns the updated profile,
+// it returns ErrProfileNotFound if the player has none. Only the fields set in the update are written, and the
+// player's scores move between the attribute leaderboards in the same transaction.
+func (mdb *MongoDBClient) UpdateProfile(playerID string, update profile.Update, updatedAt time.Time) (profile.Profile, error) {
+	fields := bson.M{"updated_at": updatedAt}
+	if update.DisplayName != nil {
+		fields["display_name"] = *update.DisplayName
+	}
+	if update.AvatarURL != nil {
+		fields["avatar_url"] = *update.AvatarURL
+	}
+	if update.Country != nil {
+		fields["country"] = *update.Country
+	}
+	if update.Region != nil {
+		fields["region"] = *update.Region
+	}
+
+	var result profile.Profile
+	err := mdb.withOutbox(playerID, func(ctx mongo.SessionContext) ([]string, error) {
+		if err := mdb.lockPlayer(ctx, playerID); err != nil {
+			return nil, err
+		}
+
+		var before profile.Profile
+		err := mdb.profiles().FindOneAndUpdate(ctx, bson.M{"_id": playerID}, bson.M{"$set": fields}).Decode(&before)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrProfileNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result = update.Apply(before)
+		result.UpdatedAt = updatedAt
+		return mdb.moveAttributeScores(ctx, before, result)
+	})
+	if errors.Is(err, ErrProfileNotFound) {
+		return profile.Profile{}, err
+	}
+	if err != nil {
+		log.Println("Failed to update profile in MongoDB:", err)
+		return profile.Profile{}, err
+	}
+	return result, nil
+}
+
+// DeleteProfile removes the profile of a player, it returns ErrProfileNotFound if the player has none.
+// The player's scores leave the attribute leaderboards of the profile in the same transaction.
+func (mdb *MongoDBClient) DeleteProfile(playerID string) error {
+	err := mdb.withOutbox(playerID, func(ctx mongo.SessionContext) ([]string, error) {
+		if err := mdb.lockPlayer(ctx, playerID); err != nil {
+			return nil, err
+		}
+
+		var before profile.Profile
+		err := mdb.profiles().FindOneAndDelete(ctx, bson.M{"_id": playerID}).Decode(&before)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrProfileNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return mdb.moveAttributeScores(ctx, before, profile.Profile{PlayerID: playerID})
+	})
+	if errors.Is(err, ErrProfileNotFound) {
+		return err
+	}
+	if err != nil {
+		log.Println("Failed to delete profile from MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// SaveStandings appends entries to their archives.
+func (mdb *MongoDBClient) SaveStandings(standings []standing.Standing) error {
+	if len(standings) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, len(standings))
+	for i, entry := range standings {
+		documents[i] = entry
+	}
+	if _, err := mdb.standings().InsertMany(mdb.Ctx, documents); err != nil {
+		log.Println("Failed to save standings in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteStandings removes every entry of an archive.
+func (mdb *MongoDBClient) DeleteStandings(boardID, archiveID string) error {
+	if _, err := mdb.standings().DeleteMany(mdb.Ctx, bson.M{"board_id": boardID, "archive_id": archiveID}); err != nil {
+		log.Println("Failed to delete standings from MongoDB:", err)
+		return err
+	}
+	return nil
 }
 
-// GetPlayerScore retrieves the score of a specific player by their ID.
-func (mdb *MongoDBClient) GetPlayerScore(playerID string) (int, error) {
-	collection := mdb.Client.Database("game").Collection("players")
-	var result player_score.PlayerScore
-	err := collection.FindOne(mdb.Ctx, bson.M{"player_id": playerID}).Decode(&result)
+// GetStandings retrieves up to limit entries of an archive after the given position, ordered by position.
+func (mdb *MongoDBClient) GetStandings(boardID, archiveID string, afterPosition, limit int64) ([]standing.Standing, error) {
+	cursor, err := mdb.standings().Find(
+		mdb.Ctx,
+		bson.M{"board_id": boardID, "archive_id": archiveID, "position": bson.M{"$gt": afterPosition}},
+		options.Find().SetSort(bson.M{"position": 1}).SetLimit(limit),
+	)
+	if err != nil {
+		log.Println("Failed to get standings from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	standings := []standing.Standing{}
+	if err := cursor.All(mdb.Ctx, &standings); err != nil {
+		log.Println("Failed to decode standing data:", err)
+		return nil, err
+	}
+	return standings, nil
+}
+
+// GetStandingPosition retrieves the position of a player in an archive.
+// It returns mongo.ErrNoDocuments if the player is not part of the archive.
+func (mdb *MongoDBClient) GetStandingPosition(boardID, archiveID, playerID string) (int64, error) {
+	var result standing.Standing
+	err := mdb.standings().FindOne(
+		mdb.Ctx,
+		bson.M{"board_id": boardID, "archive_id": archiveID, "player_id": playerID},
+		options.FindOne().SetProjection(bson.M{"position": 1}),
+	).Decode(&result)
 	if err != nil {
 		return 0, err
 	}
-	return result.Score, nil
+	return result.Position, nil
+}
+
+// GetPendingEntries retrieves up to limit pending outbox entries whose next attempt is due, in the order they were written.
+func (mdb *MongoDBClient) GetPendingEntries(now time.Time, limit int64) ([]outbox.Entry, error) {
+	filter := bson.M{"status": outbox.StatusPending, "next_attempt_at": bson.M{"$lte": now}}
+	return mdb.findEntries(filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+}
+
+// CompleteEntries marks the outbox entries applied. A TTL index removes them outbox.AppliedRetention later.
+func (mdb *MongoDBClient) CompleteEntries(ids []string, now time.Time) error {
+	update := bson.M{"$set": bson.M{"status": outbox.StatusApplied, "expires_at": now.Add(outbox.AppliedRetention)}}
+	if _, err := mdb.outbox().UpdateMany(mdb.Ctx, bson.M{"_id": bson.M{"$in": ids}}, update); err != nil {
+		log.Println("Failed to complete outbox entries in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetPlayersWithEntriesSince retrieves the IDs of the players with an outbox entry for the scope written at or after
+// the given time, whether it was applied yet or not.
+func (mdb *MongoDBClient) GetPlayersWithEntriesSince(scopeID string, since time.Time) ([]string, error) {
+	values, err := mdb.outbox().Distinct(mdb.Ctx, "player_id", bson.M{"scope_id": scopeID, "created_at": bson.M{"$gte": since}})
+	if err != nil {
+		log.Println("Failed to retrieve outbox players from MongoDB:", err)
+		return nil, err
+	}
+
+	playerIDs := make([]string, 0, len(values))
+	for _, value := range values {
+		if playerID, ok := value.(string); ok {
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	return playerIDs, nil
+}
+
+// UpdateEntry stores the status, attempts, error and next attempt time of an outbox entry.
+func (mdb *MongoDBClient) UpdateEntry(e outbox.Entry) error {
+	_, err := mdb.outbox().UpdateByID(mdb.Ctx, e.ID, bson.M{"$set": bson.M{
+		"status":          e.Status,
+		"attempts":        e.Attempts,
+		"last_error":      e.LastError,
+		"next_attempt_at": e.NextAttemptAt,
+	}})
+	if err != nil {
+		log.Println("Failed to update outbox entry in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// GetDeadEntries retrieves a page of the dead outbox entries, oldest first.
+func (mdb *MongoDBClient) GetDeadEntries(offset, limit int64) ([]outbox.Entry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetSkip(offset).SetLimit(limit)
+	return mdb.findEntries(bson.M{"status": outbox.StatusDead}, opts)
+}
+
+// RetryDeadEntry moves a dead outbox entry back to the pending entries with its attempts reset.
+// It returns ErrOutboxEntryNotFound if there is no dead entry with the ID.
+func (mdb *MongoDBClient) RetryDeadEntry(id string, now time.Time) error {
+	result, err := mdb.outbox().UpdateOne(mdb.Ctx, bson.M{"_id": id, "status": outbox.StatusDead}, bson.M{"$set": bson.M{
+		"status":          outbox.StatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+	}})
+	if err != nil {
+		log.Println("Failed to retry outbox entry in MongoDB:", err)
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrOutboxEntryNotFound
+	}
+	return nil
+}
+
+// RecordDeadEntry stores a dead outbox entry written outside a score change. A dead entry of the same scope and player
+// is updated with the entry's error instead, so a scope changed over and over is reported once.
+func (mdb *MongoDBClient) RecordDeadEntry(e outbox.Entry) error {
+	_, err := mdb.outbox().UpdateOne(
+		mdb.Ctx,
+		bson.M{"scope_id": e.ScopeID, "player_id": e.PlayerID, "status": outbox.StatusDead},
+		bson.M{
+			"$set":         bson.M{"last_error": e.LastError, "next_attempt_at": e.NextAttemptAt},
+			"$setOnInsert": bson.M{"_id": primitive.NewObjectID().Hex(), "attempts": e.Attempts, "created_at": e.CreatedAt},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Println("Failed to record dead outbox entry in MongoDB:", err)
+		return err
+	}
+	return nil
+}
+
+// findEntries retrieves the outbox entries matching the filter.
+func (mdb *MongoDBClient) findEntries(filter bson.M, opts *options.FindOptions) ([]outbox.Entry, error) {
+	cursor, err := mdb.outbox().Find(mdb.Ctx, filter, opts)
+	if err != nil {
+		log.Println("Failed to retrieve outbox entries from MongoDB:", err)
+		return nil, err
+	}
+	defer cursor.Close(mdb.Ctx)
+
+	entries := []outbox.Entry{}
+	if err := cursor.All(mdb.Ctx, &entries); err != nil {
+		log.Println("Failed to decode outbox entries from MongoDB:", err)
+		return nil, err
+	}
+	return entries, nil
 }
 
 // Connect establishes a connection to MongoDB using the provided URI.
@@ -84,9 +1316,117 @@ func (mc *MongoDBClient) Connect() {
 
 	mc.Client = client
 	log.Println("Connected to MongoDB!")
+
+	mc.ensureIndexes()
+}
+
+// ensureIndexes creates the indexes needed to keep scores unique per board, to sort them efficiently and to remove
+// the scores of ended window periods.
+// Failures are only logged, the service keeps working without the indexes.
+func (mc *MongoDBClient) ensureIndexes() {
+	_, err := mc.scores().Indexes().CreateMany(mc.Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "player_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "score", Value: -1}, {Key: "player_id", Value: -1}}},
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "score", Value: -1}, {Key: "achieved_at", Value: 1}, {Key: "player_id", Value: -1}}},
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "score", Value: -1}, {Key: "completion_ms", Value: 1}, {Key: "player_id", Value: -1}}},
+		{Keys: bson.D{{Key: "player_id", Value: 1}}},                                                     // Finds the scores moved between attribute leaderboards
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)}, // Removes the scores of ended window periods
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB indexes:", err)
+	}
+
+	_, err = mc.history().Indexes().CreateOne(mc.Ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "player_id", Value: 1}, {Key: "timestamp", Value: -1}},
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB history indexes:", err)
+	}
+
+	_, err = mc.seasons().Indexes().CreateMany(mc.Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "season_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "board_id", Value: 1}}, Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"active": true})},
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB season indexes:", err)
+	}
+
+	_, err = mc.groupMembers().Indexes().CreateMany(mc.Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "player_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "player_id", Value: 1}, {Key: "group_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB group indexes:", err)
+	}
+
+	_, err = mc.follows().Indexes().CreateMany(mc.Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "followee_id", Value: 1}, {Key: "follower_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB follow indexes:", err)
+	}
+
+	_, err = mc.snapshots().Indexes().CreateOne(mc.Ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "board_id", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB snapshot indexes:", err)
+	}
+
+	_, err = mc.standings().Indexes().CreateMany(mc.Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "archive_id", Value: 1}, {Key: "position", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "board_id", Value: 1}, {Key: "archive_id", Value: 1}, {Key: "player_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB standings indexes:", err)
+	}
+
+	_, err = mc.outbox().Indexes().CreateMany(mc.Ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "scope_id", Value: 1}, {Key: "created_at", Value: 1}}},                       // Finds the players a cache rebuild catches up with
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)}, // Removes the applied entries
+	})
+	if err != nil {
+		log.Println("Failed to create MongoDB outbox indexes:", err)
+	}
 }
 
 // Close gracefully closes the connection to MongoDB.
 func (mc *MongoDBClient) Close() {
 	mc.Client.Disconnect(mc.Ctx)
 }
+
+// sortOrder returns the sort specification that orders scores the same way as tb.Before.
+func sortOrder(tb player_score.TieBreak) bson.D {
+	order := bson.D{{Key: "score", Value: -1}}
+	if field := tb.Field(); field != "" {
+		order = append(order, bson.E{Key: field, Value: 1})
+	}
+	return append(order, bson.E{Key: "player_id", Value: -1})
+}
+
+// orderFilter returns the clauses of an $or filter matching the scores ordered before the player
+// when before is true, or after the player otherwise, using the same order as sortOrder.
+func orderFilter(tb player_score.TieBreak, player player_score.PlayerScore, before bool) bson.A {
+	scoreOp, tieOp, idOp := "$lt", "$gt", "$lt"
+	if before {
+		scoreOp, tieOp, idOp = "$gt", "$lt", "$gt"
+	}
+
+	clauses := bson.A{bson.M{"score": bson.M{scoreOp: player.Score}}}
+	field := tb.Field()
+	if field == "" {
+		return append(clauses, bson.M{"score": player.Score, "player_id": bson.M{idOp: player.PlayerID}})
+	}
+
+	var tie interface{} = player.AchievedAt
+	if tb.OrDefault() == player_score.TieBreakFastest {
+		tie = player.CompletionTime
+	}
+	return append(clauses,
+		bson.M{"score": player.Score, field: bson.M{tieOp: tie}},
+		bson.M{"score": player.Score, field: tie, "player_id": bson.M{idOp: player.PlayerID}},
+	)
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"errors"
+	"quiz/internals/domain/outbox"
+	"time"
+)
+
+// ErrOutboxEntryNotFound is returned when a dead outbox entry with the given ID does not exist.
+var ErrOutboxEntryNotFound = errors.New("outbox entry not found")
+
+// IOutboxRepository defines the operations of the outbox relay on the outbox entries. Entries are written by
+// IDBRepository together with the score changes they describe.
+type IOutboxRepository interface {
+	GetPendingEntries(now time.Time, limit int64) ([]outbox.Entry, error)         // Retrieve the pending entries whose next attempt is due, in the order they were written
+	CompleteEntries(ids []string, now time.Time) error                            // Mark the entries applied once they have been applied to the cache, they are removed outbox.AppliedRetention later
+	UpdateEntry(e outbox.Entry) error                                             // Store the status, attempts, error and next attempt time of an entry after a failed attempt
+	GetDeadEntries(offset, limit int64) ([]outbox.Entry, error)                   // Retrieve a page of the dead entries, oldest first
+	RetryDeadEntry(id string, now time.Time) error                                // Move a dead entry back to the pending entries with its attempts reset, returns ErrOutboxEntryNotFound if it does not exist
+	RecordDeadEntry(e outbox.Entry) error                                         // Store a dead entry written outside a score change, a dead entry of the same scope and player only gets its error updated
+	GetPlayersWithEntriesSince(scopeID string, since time.Time) ([]string, error) // Retrieve the IDs of the players with an entry for the scope written at or after the given time, applied or not
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"errors"
+	"quiz/internals/domain/profile"
+	"time"
+)
+
+var (
+	ErrProfileExists   = errors.New("profile already exists") // Returned when creating a profile for a player that already has one
+	ErrProfileNotFound = errors.New("profile not found")      // Returned when a player has no profile
+)
+
+// IProfileRepository defines the operations for managing player profiles in the database.
+// Changes of a profile move the player's scores between the attribute leaderboards of every board in the same
+// transaction, recording the moved scores in the outbox.
+type IProfileRepository interface {
+	CreateProfile(p profile.Profile) error                                                              // Create a new profile, returns ErrProfileExists if the player already has one
+	GetProfile(playerID string) (profile.Profile, error)                                                // Retrieve the profile of a player, returns ErrProfileNotFound if missing
+	GetProfiles(playerIDs []string) ([]profile.Profile, error)                                          // Retrieve the profiles of several players, players without a profile are left out
+	UpdateProfile(playerID string, update profile.Update, updatedAt time.Time) (profile.Profile, error) // Apply an update to a profile and return the result, returns ErrProfileNotFound if missing
+	DeleteProfile(playerID string) error                                                                // Delete the profile of a player, returns ErrProfileNotFound if missing
+}
@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"log"
+	"math"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories/cachekey"
+	"strings"
+
+	"github.com/go-redis/redis"
+)
+
+// Key prefixes of the layout used before the versioned key schema (version 1).
+const (
+	legacyLeaderboard            = "leaderboard"        // Leaderboard of the service before boards were introduced, holding raw scores
+	legacyLeaderboardPrefix      = "leaderboard:"       // Leaderboards of boards and their scopes, by scope ID
+	legacyGroupLeaderboardPrefix = "group_leaderboard:" // Group leaderboards, by board ID
+	legacyPlayerPrefix           = "player:"            // Player HASHes, by player ID
+)
+
+// legacyPlayerNameFields are the fields the name of a player was written to by the version 1 layout, in order of preference.
+var legacyPlayerNameFields = []string{"PlayerName", "name", "playername"}
+
+// KeyMigration reports the keys rewritten by MigrateKeySchema.
+type KeyMigration struct {
+	DryRun            bool  `json:"dry_run"`             // Whether the keys were only counted, without being rewritten
+	Leaderboards      int64 `json:"leaderboards"`        // Leaderboards moved into the namespace of their board
+	GroupLeaderboards int64 `json:"group_leaderboards"`  // Group leaderboards dropped, they are rebuilt on their next read
+	Players           int64 `json:"players"`             // Player HASHes rewritten with the current field names
+	Skipped           int64 `json:"skipped"`             // Keys with a legacy prefix but an unexpected type, left untouched
+	PreviousVersion   int64 `json:"previous_version"`    // Schema version the cache was at before the migration, 1 if it was never migrated
+	MigratedToVersion int64 `json:"migrated_to_version"` // Schema version the cache is at after the migration
+}
+
+// MigrateKeySchema rewrites the keys of the version 1 layout into the current key schema and records the schema
+// version in the cache. The bare leaderboard written before boards were introduced moves to the leaderboard of
+// board.DefaultID, with its raw scores turned into sort values of player_score.DefaultTieBreak. Leaderboard entries are merged into the new keys with ZADD NX, so scores written by the
+// current version of the service win over the legacy ones, and every legacy key is deleted once it was copied.
+// The migration is idempotent and may run while the service is serving requests. With dryRun set the keys are only
+// counted.
+func (rr *RedisClient) MigrateKeySchema(dryRun bool) (KeyMigration, error) {
+	report := KeyMigration{DryRun: dryRun, PreviousVersion: 1, MigratedToVersion: cachekey.Version}
+
+	previous, err := rr.Client.Get(cachekey.VersionKey).Int64()
+	if err != nil && err != redis.Nil {
+		log.Println("Failed to read the cache schema version from Redis:", err)
+		return report, err
+	}
+	if err == nil {
+		report.PreviousVersion = previous
+	}
+
+	legacyType, err := rr.Client.Type(legacyLeaderboard).Result()
+	if err != nil {
+		log.Println("Failed to read the type of key in Redis:", legacyLeaderboard, "err:", err)
+		return report, err
+	}
+	switch legacyType {
+	case "none":
+	case "zset":
+		report.Leaderboards++
+		if !dryRun {
+			if err := rr.migrateLeaderboard(legacyLeaderboard, cachekey.Leaderboard(board.DefaultID), legacySortValue); err != nil {
+				log.Println("Failed to migrate key in Redis:", legacyLeaderboard, "err:", err)
+				return report, err
+			}
+		}
+	default:
+		report.Skipped++
+	}
+
+	err = rr.scanKeys(legacyLeaderboardPrefix, "zset", &report, func(key string) error {
+		report.Leaderboards++
+		if dryRun {
+			return nil
+		}
+		return rr.migrateLeaderboard(key, cachekey.Leaderboard(strings.TrimPrefix(key, legacyLeaderboardPrefix)), nil)
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = rr.scanKeys(legacyGroupLeaderboardPrefix, "zset", &report, func(key string) error {
+		report.GroupLeaderboards++
+		if dryRun {
+			return nil
+		}
+		return rr.Client.Del(key).Err()
+	})
+	if err != nil {
+		return report, err
+	}
+
+	err = rr.scanKeys(legacyPlayerPrefix, "hash", &report, func(key string) error {
+		report.Players++
+		if dryRun {
+			return nil
+		}
+		return rr.migratePlayer(key, strings.TrimPrefix(key, legacyPlayerPrefix))
+	})
+	if err != nil {
+		return report, err
+	}
+
+	if dryRun {
+		report.MigratedToVersion = report.PreviousVersion
+		return report, nil
+	}
+	if err := rr.Client.Set(cachekey.VersionKey, cachekey.Version, 0).Err(); err != nil {
+		log.Println("Failed to write the cache schema version to Redis:", err)
+		return report, err
+	}
+	return report, nil
+}
+
+// scanKeys calls fn for every key starting with the prefix whose type is keyType, walking the keyspace with SCAN.
+// Keys of another type are counted as skipped in the report.
+func (rr *RedisClient) scanKeys(prefix, keyType string, report *KeyMigration, fn func(key string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := rr.Client.Scan(cursor, prefix+"*", 1000).Result()
+		if err != nil {
+			log.Println("Failed to scan keys in Redis:", prefix, "err:", err)
+			return err
+		}
+
+		for _, key := range keys {
+			t, err := rr.Client.Type(key).Result()
+			if err != nil {
+				log.Println("Failed to read the type of key in Redis:", key, "err:", err)
+				return err
+			}
+			if t != keyType {
+				report.Skipped++
+				continue
+			}
+			if err := fn(key); err != nil {
+				log.Println("Failed to migrate key in Redis:", key, "err:", err)
+				return err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// legacySortValue turns a raw score of the bare legacy leaderboard into a sort value of player_score.DefaultTieBreak.
+// The legacy entries carry no achievement time, so they rank above the players that reach the same score later.
+func legacySortValue(value float64) float64 {
+	return player_score.DefaultTieBreak.SortValue(player_score.PlayerScore{Score: int(math.Floor(value))})
+}
+
+// migrateLeaderboard copies the entries of the legacy leaderboard into the new key in batches, keeping the entries
+// already present in the new key, and deletes the legacy leaderboard. Entries are copied instead of renamed, since
+// the keys may live in different cluster slots. A non-nil convert rewrites the value of every copied entry.
+func (rr *RedisClient) migrateLeaderboard(legacyKey, key string, convert func(float64) float64) error {
+	const batch = 1000
+	for start := int64(0); ; start += batch {
+		members, err := rr.Client.ZRangeWithScores(legacyKey, start, start+batch-1).Result()
+		if err != nil {
+			return err
+		}
+		if convert != nil {
+			for i := range members {
+				members[i].Score = convert(members[i].Score)
+			}
+		}
+		if len(members) > 0 {
+			if err := rr.Client.ZAddNX(key, members...).Err(); err != nil {
+				return err
+			}
+		}
+		if len(members) < batch {
+			break
+		}
+	}
+	return rr.Client.Del(legacyKey).Err()
+}
+
+// migratePlayer writes the ID and the name of the legacy player HASH to the player's HASH, keeping the fields already
+// present in it, and deletes the legacy HASH.
+func (rr *RedisClient) migratePlayer(legacyKey, playerID string) error {
+	fields, err := rr.Client.HGetAll(legacyKey).Result()
+	if err != nil {
+		return err
+	}
+
+	pipe := rr.Client.TxPipeline()
+	pipe.HSetNX(cachekey.Player(playerID), cachekey.FieldPlayerID, playerID)
+	for _, field := range legacyPlayerNameFields {
+		if name := fields[field]; name != "" {
+			pipe.HSetNX(cachekey.Player(playerID), cachekey.FieldPlayerName, name)
+			break
+		}
+	}
+	pipe.Del(legacyKey)
+	_, err = pipe.Exec()
+	return err
+}
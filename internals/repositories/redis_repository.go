@@ -2,9 +2,16 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"log"
+	"math"
+	"math/rand"
 	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories/cachekey"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-redis/redis"
 )
@@ -15,51 +22,70 @@ type RedisClient struct {
 	Addr     string
 	Password string
 	DB       int
+	Expiry   CacheExpiry
 
 	Client *redis.Client
 }
 
-// NewRedisClient initializes a new Redis client with the given context, address, password, and database number.
-func NewRedisClient(ctx context.Context, addr, password string, db int) *RedisClient {
-	return &RedisClient{Ctx: ctx, Addr: addr, Password: password, DB: db}
+// NewRedisClient initializes a new Redis client with the given context, address, password, database number, and key expiry.
+func NewRedisClient(ctx context.Context, addr, password string, db int, expiry CacheExpiry) *RedisClient {
+	return &RedisClient{Ctx: ctx, Addr: addr, Password: password, DB: db, Expiry: expiry}
 }
 
-// UpdatePlayerScore updates the player's score in the ZSET (leaderboard) identified by the key.
-// It either adds a new entry or updates an existing player's score in the ZSET.
-func (rr *RedisClient) UpdatePlayerScore(key, playerID string, score float64) error {
-	// Add or update the player's score in the ZSET
-	err := rr.Client.ZAdd(key, redis.Z{
-		Score:  score,    // The player's new score
-		Member: playerID, // The player ID is the member in the ZSET
-	}).Err()
+// zaddWarmScript adds a member to a ZSET with the ZADD flag in ARGV[1] (none, GT, LT or NX), but only when the ZSET
+// exists, so a write never turns a cold leaderboard into one holding just the written player. It returns the number
+// of changed members, or -1 when the ZSET does not exist.
+var zaddWarmScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+if ARGV[1] == "" then
+	return redis.call("ZADD", KEYS[1], "CH", ARGV[2], ARGV[3])
+end
+return redis.call("ZADD", KEYS[1], ARGV[1], "CH", ARGV[2], ARGV[3])`)
+
+// zaddWarm adds the player to the ZSET with the ZADD flag (none, GT, LT or NX, GT and LT need Redis 6.2 or newer) if
+// the ZSET exists. It reports whether the ZSET exists and whether it was changed.
+func (rr *RedisClient) zaddWarm(key, flag, playerID string, score float64) (bool, bool, error) {
+	changed, err := zaddWarmScript.Run(rr.Client, []string{key}, flag, score, playerID).Int64()
 	if err != nil {
 		log.Println("Failed to update player score in Redis ZSET:", err)
-		return err
+		return false, false, err
 	}
-
-	return nil
+	return changed >= 0, changed > 0, nil
 }
 
 // UpdatePlayerCache updates both the leaderboard and the player's details in the Redis cache.
-// It updates the player's score in the ZSET and stores additional player details in a HASH.
-func (rr *RedisClient) UpdatePlayerCache(key string, playerScore player_score.PlayerScore) error {
+// Under the keep_best and keep_lowest update policies the ZSET entry is only replaced by a better score, so cache
+// writes arriving out of order cannot undo a better one, and the HASH is left untouched when the score is kept.
+// A cold leaderboard is left cold, only the player's HASH is updated under the keep_latest policy.
+// It updates the player's sort value in the ZSET and stores additional player details in a HASH, and refreshes the
+// expiry of both.
+func (rr *RedisClient) UpdatePlayerCache(key string, tb player_score.TieBreak, up player_score.UpdatePolicy, playerScore player_score.PlayerScore) error {
 	// Update the ZSET leaderboard (find and replace player's score)
-	err := rr.UpdatePlayerScore(key, playerScore.PlayerID, float64(playerScore.Score))
-	if err != nil {
-		log.Println("Failed to update player score in Redis ZSET:", err)
+	flag := ""
+	switch up.OrDefault() {
+	case player_score.UpdateKeepBest:
+		flag = "GT"
+	case player_score.UpdateKeepLowest:
+		flag = "LT"
+	}
+	_, changed, err := rr.zaddWarm(key, flag, playerScore.PlayerID, tb.SortValue(playerScore))
+	if err != nil || (flag != "" && !changed) {
 		return err
 	}
 
-	// Update the HASH for the player with new details (ID, Name, and Score)
+	// Update the HASH for the player with new details (ID and Name), the score is kept by the leaderboards
 	playerHash := map[string]interface{}{
-		"PlayerID":   playerScore.PlayerID,
-		"PlayerName": playerScore.PlayerName,
-		"Score":      playerScore.Score,
+		cachekey.FieldPlayerID:   playerScore.PlayerID,
+		cachekey.FieldPlayerName: playerScore.PlayerName,
 	}
 
-	// Use HMSet to store player details in Redis HASH
-	err = rr.Client.HMSet("player:"+playerScore.PlayerID, playerHash).Err()
-	if err != nil {
+	// Use HMSet to store player details in Redis HASH, and push back the expiry of the keys
+	pipe := rr.Client.Pipeline()
+	pipe.HMSet(cachekey.Player(playerScore.PlayerID), playerHash)
+	rr.touch(pipe, key, false, playerScore.PlayerID)
+	if _, err := pipe.Exec(); err != nil {
 		log.Println("Failed to update Redis HASH for player:", playerScore.PlayerID, "err:", err)
 		return err
 	}
@@ -67,87 +93,531 @@ func (rr *RedisClient) UpdatePlayerCache(key string, playerScore player_score.Pl
 	return nil
 }
 
-// GetSetByKey fetches the sorted set from Redis identified by the key and retrieves additional player details from the HASH.
-// It returns a list of PlayerScore objects with their IDs, names, and scores.
-func (rr *RedisClient) GetSetByKey(key string) ([]player_score.PlayerScore, error) {
-	// Retrieve the sorted set from Redis
-	zSet, err := rr.Client.ZRevRangeWithScores(key, 0, -1).Result()
+// touch adds the commands refreshing the sliding expiry of the leaderboard and the players' HASHes to the pipeline.
+// Reads also record the access of the leaderboard's board for the eviction of cold boards; writes do not, so writes
+// to a cold or evicted board never bring it back into the access ranking. Expiring a missing key does nothing.
+// The leaderboards of window periods keep the expiry set by ExpireAt.
+func (rr *RedisClient) touch(pipe redis.Pipeliner, key string, read bool, playerIDs ...string) {
+	if scopeID, ok := cachekey.ScopeOf(key); rr.Expiry.LeaderboardTTL > 0 && !(ok && window.IsPeriodScope(scopeID)) {
+		pipe.Expire(key, rr.Expiry.LeaderboardTTL)
+	}
+	if boardID, ok := cachekey.BoardOf(key); ok && read {
+		pipe.ZAdd(cachekey.BoardAccess, redis.Z{Score: float64(time.Now().Unix()), Member: boardID})
+	}
+	if rr.Expiry.PlayerTTL > 0 {
+		for _, playerID := range playerIDs {
+			pipe.Expire(cachekey.Player(playerID), rr.Expiry.PlayerTTL)
+		}
+	}
+}
+
+// GetSetByKey fetches a page of the sorted set from Redis identified by the key and retrieves additional player details from the HASH.
+// It returns a list of PlayerScore objects with their IDs, names, and scores. The names of the whole page are fetched in one
+// round trip, see joinPlayerNames.
+func (rr *RedisClient) GetSetByKey(key string, tb player_score.TieBreak, page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+	start := page.Offset
+	if page.After != nil {
+		position, err := rr.positionAfter(key, tb.SortValue(page.After.Player()), page.After.PlayerID)
+		if err != nil {
+			log.Println("Failed to resolve page cursor in Redis:", err)
+			return nil, err
+		}
+		start = position
+	}
+
+	stop := int64(-1) // Read until the end of the set when no limit is given
+	if page.Limit > 0 {
+		stop = start + page.Limit - 1
+	}
+
+	// Retrieve the requested range of the sorted set from Redis
+	zSet, err := rr.Client.ZRevRangeWithScores(key, start, stop).Result()
 	if err != nil {
 		log.Println("Failed to retrieve sorted set from Redis:", err)
 		return nil, err
 	}
 
-	// Iterate through the ZSET and fetch corresponding player details from the HASH
+	return rr.joinPlayerNames(key, tb, zSet)
+}
+
+// GetSetMembers returns the entries of the given players in the sorted set identified by the key in descending score order,
+// joined with the player details from the HASH. The players are intersected with the set by ZINTERSTORE: they are
+// written to a temporary set, intersected into a temporary sorted set that keeps the leaderboard's sort values, and
+// both are deleted again, all inside one MULTI block so the temporary keys never outlive the call.
+func (rr *RedisClient) GetSetMembers(key string, tb player_score.TieBreak, playerIDs []string) ([]player_score.PlayerScore, error) {
+	if len(playerIDs) == 0 {
+		return []player_score.PlayerScore{}, nil
+	}
+
+	members := make([]interface{}, len(playerIDs))
+	for i, playerID := range playerIDs {
+		members[i] = playerID
+	}
+
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatUint(uint64(rand.Uint32()), 36)
+	membersKey, intersectionKey := key+":members:"+suffix, key+":intersection:"+suffix
+
+	pipe := rr.Client.TxPipeline()
+	pipe.SAdd(membersKey, members...)
+	pipe.ZInterStore(intersectionKey, redis.ZStore{Weights: []float64{1, 0}, Aggregate: "SUM"}, key, membersKey) // Set members score 1, weighted away
+	zSetCmd := pipe.ZRevRangeWithScores(intersectionKey, 0, -1)
+	pipe.Del(membersKey, intersectionKey)
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to intersect sorted set in Redis:", err)
+		return nil, err
+	}
+
+	return rr.joinPlayerNames(key, tb, zSetCmd.Val())
+}
+
+// joinPlayerNames builds the PlayerScore objects of the ZSET entries, restoring the score and tie breaking field from the
+// sort value, and joins them with the names from the player HASHes. The HGETs are sent in a single pipeline, so a page
+// costs one round trip however long it is. A missing HASH does not fail the read: the entry is returned without a name,
+// which the service replaces with the profile's display name when there is one. The expiry of the leaderboard identified
+// by the key and of the HASHes is refreshed in the same pipeline.
+func (rr *RedisClient) joinPlayerNames(key string, tb player_score.TieBreak, zSet []redis.Z) ([]player_score.PlayerScore, error) {
 	playerScores := make([]player_score.PlayerScore, len(zSet))
+	if len(zSet) == 0 {
+		return playerScores, nil
+	}
+
+	pipe := rr.Client.Pipeline()
+	nameCmds := make([]*redis.StringCmd, len(zSet))
+	playerIDs := make([]string, len(zSet))
 	for i, z := range zSet {
-		playerID := z.Member.(string)
+		playerScores[i] = tb.FromSortValue(z.Member.(string), z.Score)
+		playerIDs[i] = playerScores[i].PlayerID
+		nameCmds[i] = pipe.HGet(cachekey.Player(playerIDs[i]), cachekey.FieldPlayerName)
+	}
+	rr.touch(pipe, key, true, playerIDs...)
 
-		// Fetch the playername from the HASH
-		playername, err := rr.Client.HGet("player:"+playerID, "PlayerName").Result()
-		if err != nil {
-			log.Println("Failed to retrieve playername from Redis:", err)
-			return nil, err
-		}
+	// Exec reports the first failed command, a missing HASH only fails its own HGET with redis.Nil
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		log.Println("Failed to retrieve playernames from Redis:", err)
+		return nil, err
+	}
 
-		// Construct PlayerScore object
-		playerScores[i] = player_score.PlayerScore{
-			PlayerID:   playerID,
-			Score:      int(z.Score), // Convert float score to int
-			PlayerName: playername,
+	missing := 0
+	for i, cmd := range nameCmds {
+		if cmd.Err() == redis.Nil {
+			missing++
+			continue
 		}
+		playerScores[i].PlayerName = cmd.Val()
+	}
+	if missing > 0 {
+		log.Println("Player HASHes missing from Redis, returning entries without names:", missing)
 	}
 
 	return playerScores, nil
 }
 
-// GetRecordByKey retrieves a player's score and name from Redis using their player ID.
-// It first retrieves the score from the sorted set and then fetches the name from the HASH.
-func (rr *RedisClient) GetRecordByKey(playerID string) (player_score.PlayerScore, error) {
-	// Retrieve the score from the sorted set (assuming the key is for a sorted set of scores)
-	scoreResult, err := rr.Client.Get(playerID).Result()
+// GetSetSize counts the members of the sorted set identified by the key.
+func (rr *RedisClient) GetSetSize(key string) (int64, error) {
+	size, err := rr.Client.ZCard(key).Result()
 	if err != nil {
-		log.Println("Failed to get score from Redis:", err)
-		return player_score.PlayerScore{}, err
+		log.Println("Failed to get sorted set size from Redis:", err)
+		return 0, err
+	}
+	return size, nil
+}
+
+// positionAfter returns the zero based position in the ZSET of the first member ranked below the cursor,
+// given as the cursor's sort value and player ID. The rank of the cursor member is used when it still has
+// the same sort value, otherwise the position is derived from the number of members with a higher value
+// and the tied members that sort before it.
+func (rr *RedisClient) positionAfter(key string, value float64, playerID string) (int64, error) {
+	current, err := rr.Client.ZScore(key, playerID).Result()
+	if err == nil && current == value {
+		rank, err := rr.Client.ZRevRank(key, playerID).Result()
+		if err == nil {
+			return rank + 1, nil
+		}
 	}
 
-	// Convert the score result to an integer
-	score, _ := strconv.Atoi(scoreResult)
+	bound := strconv.FormatFloat(value, 'f', -1, 64)
+	higher, err := rr.Client.ZCount(key, "("+bound, "+inf").Result()
+	if err != nil {
+		return 0, err
+	}
 
-	// Retrieve the player's name from the hash stored under "player:playerID"
-	playerName, err := rr.Client.HGet("player:"+playerID, "name").Result()
+	// Members sharing the cursor's value are ordered in reverse lexicographical order
+	tied, err := rr.Client.ZRevRangeByScore(key, redis.ZRangeBy{Min: bound, Max: bound}).Result()
 	if err != nil {
-		log.Println("Failed to get player name from Redis:", err)
+		return 0, err
+	}
+	for _, member := range tied {
+		if member >= playerID {
+			higher++
+		}
+	}
+	return higher, nil
+}
+
+// FillPlayerCache adds the player's score to the ZSET (leaderboard) identified by the key and the player's details to
+// their HASH, without replacing an entry or field that is already cached (ZADD NX and HSETNX). It is used to populate
+// the cache from the database, where a concurrent score update must win over the value that was read.
+// A cold leaderboard is left cold, the player is then not cached at all.
+func (rr *RedisClient) FillPlayerCache(key string, tb player_score.TieBreak, playerScore player_score.PlayerScore) error {
+	warm, _, err := rr.zaddWarm(key, "NX", playerScore.PlayerID, tb.SortValue(playerScore))
+	if err != nil || !warm {
+		return err
+	}
+
+	pipe := rr.Client.Pipeline()
+	pipe.HSetNX(cachekey.Player(playerScore.PlayerID), cachekey.FieldPlayerID, playerScore.PlayerID)
+	pipe.HSetNX(cachekey.Player(playerScore.PlayerID), cachekey.FieldPlayerName, playerScore.PlayerName)
+	rr.touch(pipe, key, false, playerScore.PlayerID)
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to fill player cache in Redis:", playerScore.PlayerID, "err:", err)
+		return err
+	}
+	return nil
+}
+
+// GetRecordByKey retrieves a player's entry in the leaderboard identified by the key, joined with the name from the
+// player's HASH. The score and tie breaking field are restored from the sort value in the ZSET.
+// It returns redis.Nil if the player is not a member of the leaderboard, a missing HASH only leaves the name empty.
+func (rr *RedisClient) GetRecordByKey(key string, tb player_score.TieBreak, playerID string) (player_score.PlayerScore, error) {
+	pipe := rr.Client.Pipeline()
+	valueCmd := pipe.ZScore(key, playerID)
+	nameCmd := pipe.HGet(cachekey.Player(playerID), cachekey.FieldPlayerName)
+	rr.touch(pipe, key, true, playerID)
+	if _, err := pipe.Exec(); err != nil && err != redis.Nil {
+		log.Println("Failed to get player record from Redis:", err)
+		return player_score.PlayerScore{}, err
+	}
+	if err := valueCmd.Err(); err != nil {
 		return player_score.PlayerScore{}, err
 	}
 
-	// Return the PlayerScore struct with PlayerID, PlayerName, and Score
-	return player_score.PlayerScore{
-		PlayerID:   playerID,
-		PlayerName: playerName,
-		Score:      score,
-	}, nil
+	playerScore := tb.FromSortValue(playerID, valueCmd.Val())
+	playerScore.PlayerName = nameCmd.Val()
+	return playerScore, nil
 }
 
-// InsertRecord inserts a player's score into the Redis leaderboard (ZSET) and stores the player's name in a HASH.
-// It stores the player's score in the ZSET and the player's name in the corresponding HASH for future lookups.
-func (rr *RedisClient) InsertRecord(key, playerID, playername string, score float64) error {
-	// Add the player score to the ZSET
-	err := rr.Client.ZAdd(key, redis.Z{
-		Score:  score,
-		Member: playerID,
-	}).Err()
+// GetRank retrieves the position (starting at 1) and score of a player in the sorted set identified by the key using ZREVRANK.
+// It returns redis.Nil if the player is not a member of the set.
+func (rr *RedisClient) GetRank(key, playerID string) (int64, int, error) {
+	pipe := rr.Client.Pipeline()
+	rankCmd := pipe.ZRevRank(key, playerID)
+	scoreCmd := pipe.ZScore(key, playerID)
+	rr.touch(pipe, key, true)
+	if _, err := pipe.Exec(); err != nil {
+		if err != redis.Nil {
+			log.Println("Failed to get player rank from Redis:", err)
+		}
+		return 0, 0, err
+	}
+
+	return rankCmd.Val() + 1, int(math.Floor(scoreCmd.Val())), nil
+}
+
+// AddToSet adds the players' sort values to the ZSET identified by the key and writes their names to their HASHes,
+// all in a single pipeline. The keys get the sliding expiry, which RENAME carries over to the rebuilt leaderboard.
+func (rr *RedisClient) AddToSet(key string, tb player_score.TieBreak, players []player_score.PlayerScore) error {
+	if len(players) == 0 {
+		return nil
+	}
+
+	members := make([]redis.Z, len(players))
+	ids := make([]string, len(players))
+	pipe := rr.Client.Pipeline()
+	for i, player := range players {
+		members[i] = redis.Z{Score: tb.SortValue(player), Member: player.PlayerID}
+		ids[i] = player.PlayerID
+		pipe.HMSet(cachekey.Player(player.PlayerID), map[string]interface{}{
+			cachekey.FieldPlayerID:   player.PlayerID,
+			cachekey.FieldPlayerName: player.PlayerName,
+		})
+	}
+	pipe.ZAdd(key, members...)
+	rr.touch(pipe, key, true, ids...) // Rebuilds run on behalf of a read, or a warmup that creates the leaderboard
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to add players to Redis ZSET:", key, "err:", err)
+		return err
+	}
+	return nil
+}
+
+// replaceSetScript renames the temporary key over the leaderboard, or deletes the leaderboard when nothing was written
+// to the temporary key, since RENAME fails on a missing key. When the generation in KEYS[3] no longer matches ARGV[1]
+// the scores were reset while the temporary key was built, so it is deleted instead and 0 is returned.
+var replaceSetScript = redis.NewScript(`
+if (tonumber(redis.call("GET", KEYS[3])) or 0) ~= tonumber(ARGV[1]) then
+	redis.call("DEL", KEYS[1])
+	return 0
+end
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	redis.call("RENAME", KEYS[1], KEYS[2])
+else
+	redis.call("DEL", KEYS[2])
+end
+return 1`)
+
+// ReplaceSet replaces the ZSET identified by the key with the one built under the temporary key, in one atomic step,
+// so readers see either the old or the new leaderboard, unless the generation counter no longer holds the generation
+// the temporary key was built under. It reports whether the leaderboard was replaced. The three keys must share a
+// cluster slot, see cachekey.RebuildTemp and cachekey.Generation.
+func (rr *RedisClient) ReplaceSet(tempKey, key, generationKey string, generation int64) (bool, error) {
+	replaced, err := replaceSetScript.Run(rr.Client, []string{tempKey, key, generationKey}, generation).Int64()
+	if err != nil {
+		log.Println("Failed to replace Redis ZSET:", key, "err:", err)
+		return false, err
+	}
+	return replaced == 1, nil
+}
+
+// GetGeneration reads the generation counter identified by the key, a missing key is generation zero.
+func (rr *RedisClient) GetGeneration(key string) (int64, error) {
+	generation, err := rr.Client.Get(key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
 	if err != nil {
-		log.Println("Failed to insert score into Redis:", err)
+		log.Println("Failed to read generation from Redis:", key, "err:", err)
+		return 0, err
+	}
+	return generation, nil
+}
+
+// BumpGeneration increments the generation counter identified by the key with INCR and sets its expiry.
+func (rr *RedisClient) BumpGeneration(key string, ttl time.Duration) error {
+	pipe := rr.Client.TxPipeline()
+	pipe.Incr(key)
+	pipe.Expire(key, ttl)
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to bump generation in Redis:", key, "err:", err)
+		return err
+	}
+	return nil
+}
+
+// SetMarker sets the marker key with an empty value, expiring after the TTL.
+func (rr *RedisClient) SetMarker(key string, ttl time.Duration) error {
+	if err := rr.Client.Set(key, "", ttl).Err(); err != nil {
+		log.Println("Failed to set marker in Redis:", key, "err:", err)
 		return err
 	}
+	return nil
+}
+
+// HasMarker reports whether the marker key is set. Expired markers are removed by Redis.
+func (rr *RedisClient) HasMarker(key string) (bool, error) {
+	n, err := rr.Client.Exists(key).Result()
+	if err != nil {
+		log.Println("Failed to check marker in Redis:", key, "err:", err)
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// releaseLockScript deletes the lock key only when it still holds the caller's token, so a holder whose lock expired
+// never releases the lock taken over by someone else.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
 
-	// Store the player's playername in a HASH
-	err = rr.Client.HSet("player:"+playerID, "playername", playername).Err()
+// AcquireLock takes the lock identified by the key with SET NX PX, storing the holder's token.
+// It reports false when the lock is held by someone else.
+func (rr *RedisClient) AcquireLock(key, token string, ttl time.Duration) (bool, error) {
+	acquired, err := rr.Client.SetNX(key, token, ttl).Result()
 	if err != nil {
-		log.Println("Failed to store player details in Redis HASH:", err)
+		log.Println("Failed to acquire lock in Redis:", key, "err:", err)
+		return false, err
+	}
+	return acquired, nil
+}
+
+// renewLockScript pushes back the expiry of the lock key only when it still holds the caller's token.
+var renewLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// RenewLock pushes back the expiry of the lock identified by the key to the TTL if it is still held with the token.
+// It reports false when the lock expired or was taken over by someone else.
+func (rr *RedisClient) RenewLock(key, token string, ttl time.Duration) (bool, error) {
+	renewed, err := renewLockScript.Run(rr.Client, []string{key}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		log.Println("Failed to renew lock in Redis:", key, "err:", err)
+		return false, err
+	}
+	return renewed == 1, nil
+}
+
+// ReleaseLock releases the lock identified by the key if it is still held with the token.
+func (rr *RedisClient) ReleaseLock(key, token string) error {
+	if err := releaseLockScript.Run(rr.Client, []string{key}, token).Err(); err != nil {
+		log.Println("Failed to release lock in Redis:", key, "err:", err)
 		return err
 	}
+	return nil
+}
+
+// DeleteKey removes the leaderboard (ZSET) or marker identified by the key.
+// Player HASHes are left untouched because they may still be referenced by other leaderboards.
+func (rr *RedisClient) DeleteKey(key string) error {
+	if err := rr.Client.Del(key).Err(); err != nil {
+		log.Println("Failed to delete key from Redis:", key, "err:", err)
+		return err
+	}
+	return nil
+}
+
+// ExpireAt sets the expiry of the leaderboard identified by the key with EXPIREAT. Expiring a missing key does nothing,
+// and a time in the past removes the key.
+func (rr *RedisClient) ExpireAt(key string, at time.Time) error {
+	if err := rr.Client.ExpireAt(key, at).Err(); err != nil {
+		log.Println("Failed to set expiry in Redis:", key, "err:", err)
+		return err
+	}
+	return nil
+}
+
+// SetMemberScore adds or updates a bare member of the ZSET identified by the key, without any player HASH,
+// and refreshes the expiry of the ZSET.
+func (rr *RedisClient) SetMemberScore(key, member string, score float64) error {
+	pipe := rr.Client.Pipeline()
+	pipe.ZAdd(key, redis.Z{Score: score, Member: member})
+	rr.touch(pipe, key, false)
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to update member score in Redis ZSET:", err)
+		return err
+	}
+	return nil
+}
+
+// RemoveMember removes a bare member from the ZSET identified by the key.
+func (rr *RedisClient) RemoveMember(key, member string) error {
+	if err := rr.Client.ZRem(key, member).Err(); err != nil {
+		log.Println("Failed to remove member from Redis ZSET:", err)
+		return err
+	}
+	return nil
+}
 
+// GetMemberScores retrieves a page of the bare members of the ZSET identified by the key in descending score order,
+// and refreshes the expiry of the ZSET. A limit of zero or less returns every member after the offset.
+func (rr *RedisClient) GetMemberScores(key string, offset, limit int64) ([]MemberScore, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = offset + limit - 1
+	}
+
+	pipe := rr.Client.Pipeline()
+	membersCmd := pipe.ZRevRangeWithScores(key, offset, stop)
+	rr.touch(pipe, key, true)
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to retrieve members from Redis ZSET:", err)
+		return nil, err
+	}
+
+	members := membersCmd.Val()
+	scores := make([]MemberScore, len(members))
+	for i, member := range members {
+		scores[i] = MemberScore{Member: member.Member.(string), Score: member.Score}
+	}
+	return scores, nil
+}
+
+// DeleteKeysWithPrefix removes every key starting with the prefix, see DeleteKeysMatching.
+// The prefix must not contain glob characters.
+func (rr *RedisClient) DeleteKeysWithPrefix(prefix string) error {
+	return rr.DeleteKeysMatching(prefix + "*")
+}
+
+// DeleteKeysMatching removes every key matching the glob pattern, walking the keyspace with SCAN
+// so Redis is never blocked by a single KEYS call.
+func (rr *RedisClient) DeleteKeysMatching(pattern string) error {
+	var cursor uint64
+	for {
+		keys, next, err := rr.Client.Scan(cursor, pattern, 1000).Result()
+		if err != nil {
+			log.Println("Failed to scan keys in Redis:", pattern, "err:", err)
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := rr.Client.Del(keys...).Err(); err != nil {
+				log.Println("Failed to delete keys from Redis:", pattern, "err:", err)
+				return err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// MemoryUsage reports the number of bytes Redis has allocated, the used_memory field of INFO memory.
+func (rr *RedisClient) MemoryUsage() (int64, error) {
+	info, err := rr.Client.Info("memory").Result()
+	if err != nil {
+		log.Println("Failed to read memory usage from Redis:", err)
+		return 0, err
+	}
+
+	for _, line := range strings.Split(info, "\r\n") {
+		if value := strings.TrimPrefix(line, "used_memory:"); value != line {
+			return strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return 0, errors.New("used_memory missing from the Redis memory info")
+}
+
+// ColdestBoards returns the IDs of the boards whose leaderboards were accessed, least recently accessed first.
+func (rr *RedisClient) ColdestBoards(limit int64) ([]string, error) {
+	boardIDs, err := rr.Client.ZRange(cachekey.BoardAccess, 0, limit-1).Result()
+	if err != nil {
+		log.Println("Failed to retrieve board accesses from Redis:", err)
+		return nil, err
+	}
+	return boardIDs, nil
+}
+
+// EvictBoard deletes every leaderboard of the board, walking its keys with SCAN, and its group leaderboard, and forgets
+// the board's last access. Leaderboards being rebuilt are kept, since deleting their temporary key would let the rebuild
+// swap in a partial one.
+func (rr *RedisClient) EvictBoard(boardID string) error {
+	prefix := cachekey.Leaderboard(boardID)
+	var cursor uint64
+	for {
+		keys, next, err := rr.Client.Scan(cursor, prefix+"*", 1000).Result()
+		if err != nil {
+			log.Println("Failed to scan keys in Redis:", prefix, "err:", err)
+			return err
+		}
+
+		var evicted []string
+		for _, key := range keys {
+			if !cachekey.IsRebuildTemp(key) {
+				evicted = append(evicted, key)
+			}
+		}
+		if len(evicted) > 0 {
+			if err := rr.Client.Del(evicted...).Err(); err != nil {
+				log.Println("Failed to evict keys from Redis:", prefix, "err:", err)
+				return err
+			}
+		}
+
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+
+	pipe := rr.Client.Pipeline()
+	pipe.Del(cachekey.GroupLeaderboard(boardID))
+	pipe.ZRem(cachekey.BoardAccess, boardID)
+	if _, err := pipe.Exec(); err != nil {
+		log.Println("Failed to evict group leaderboard and board access from Redis:", boardID, "err:", err)
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/player_score"
+)
+
+// ErrResumeTokenLost is returned by WatchScores when the stream cannot be resumed from the given token anymore,
+// because the changes after it are no longer kept by the database.
+var ErrResumeTokenLost = errors.New("the score stream cannot be resumed from the stored token")
+
+// ScoreDocumentChange is a change of a score document read from the database's stream of changes.
+type ScoreDocumentChange struct {
+	ScopeID string                   // Scope (a board or one of its windows or attribute leaderboards) of the score
+	Player  player_score.PlayerScore // Score after the change, or as it was before a delete
+	Deleted bool                     // Whether the score was deleted
+}
+
+// IScoreStreamRepository defines the operations for following every change of the stored scores, including the ones
+// written by other applications, and for keeping the position reached in the stream.
+type IScoreStreamRepository interface {
+	WatchScores(ctx context.Context, resumeToken []byte, handle func(change ScoreDocumentChange) error, checkpoint func(resumeToken []byte) error) error // Call handle for every score change after the token (or from now without one), and checkpoint with the position reached after every batch and every poll without changes, until ctx is done or a callback fails
+	GetResumeToken(stream string) ([]byte, error)                                                                                                        // Retrieve the stored resume token of the stream, nil if there is none
+	SaveResumeToken(stream string, token []byte) error                                                                                                   // Store the resume token of the stream, a nil token removes it
+}
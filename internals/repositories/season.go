@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"errors"
+	"quiz/internals/domain/season"
+	"time"
+)
+
+var (
+	ErrSeasonExists   = errors.New("season already exists")              // Returned when creating a season whose ID is already taken on the board
+	ErrSeasonActive   = errors.New("board already has an active season") // Returned when starting a season while another one is running on the board
+	ErrSeasonNotFound = errors.New("season not found")                   // Returned when a season (or an active season) does not exist
+)
+
+// ISeasonRepository defines the operations for managing the seasons of the boards in the database.
+type ISeasonRepository interface {
+	CreateSeason(s season.Season) error                                       // Create a new active season, returns ErrSeasonExists or ErrSeasonActive
+	GetSeasons(boardID string) ([]season.Season, error)                       // Retrieve every season of the board, newest first
+	GetSeason(boardID, seasonID string) (season.Season, error)                // Retrieve a single season, returns ErrSeasonNotFound if missing
+	EndSeason(boardID, seasonID string, endedAt time.Time, total int64) error // Mark the active season as ended, returns ErrSeasonNotFound if it is not active
+}
@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"errors"
+	"quiz/internals/domain/snapshot"
+)
+
+var (
+	ErrSnapshotExists   = errors.New("snapshot already exists") // Returned when taking a snapshot whose name is already taken on the board
+	ErrSnapshotNotFound = errors.New("snapshot not found")      // Returned when a snapshot with the given name does not exist
+)
+
+// ISnapshotRepository defines the operations for managing the named snapshots of the boards in the database.
+// The ranked players of a snapshot are kept by IStandingsRepository.
+type ISnapshotRepository interface {
+	CreateSnapshot(s snapshot.Snapshot) error                    // Create a new, incomplete snapshot, returns ErrSnapshotExists if the name is taken
+	CompleteSnapshot(boardID, name string, total int64) error    // Mark a snapshot as complete with its number of players, returns ErrSnapshotNotFound if missing
+	GetSnapshots(boardID string) ([]snapshot.Snapshot, error)    // Retrieve every snapshot of the board, newest first
+	GetSnapshot(boardID, name string) (snapshot.Snapshot, error) // Retrieve a single snapshot, returns ErrSnapshotNotFound if missing
+	DeleteSnapshot(boardID, name string) error                   // Delete a snapshot, returns ErrSnapshotNotFound if missing
+}
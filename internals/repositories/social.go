@@ -0,0 +1,11 @@
+package repositories
+
+import "quiz/internals/domain/social"
+
+// ISocialRepository defines the operations for managing the social graph of follow edges between players in the database.
+type ISocialRepository interface {
+	Follow(f social.Follow) error                   // Store a follow edge, following a player twice is a no-op
+	Unfollow(followerID, followeeID string) error   // Remove a follow edge, removing a missing edge is a no-op
+	GetFollowing(playerID string) ([]string, error) // Retrieve the IDs of the players the player follows
+	GetFollowers(playerID string) ([]string, error) // Retrieve the IDs of the players following the player
+}
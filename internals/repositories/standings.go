@@ -0,0 +1,12 @@
+package repositories
+
+import "quiz/internals/domain/standing"
+
+// IStandingsRepository defines the operations for storing archived leaderboards, such as the final standings of a season.
+// Archived entries are only written while an archive is taken and never change afterwards.
+type IStandingsRepository interface {
+	SaveStandings(standings []standing.Standing) error                                               // Append entries to their archives
+	DeleteStandings(boardID, archiveID string) error                                                 // Remove every entry of an archive, used to restart an interrupted archival
+	GetStandings(boardID, archiveID string, afterPosition, limit int64) ([]standing.Standing, error) // Retrieve up to limit entries of an archive after the given position, ordered by position
+	GetStandingPosition(boardID, archiveID, playerID string) (int64, error)                          // Retrieve the position of a player in an archive, returns mongo.ErrNoDocuments if missing
+}
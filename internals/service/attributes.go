@@ -0,0 +1,43 @@
+package service
+
+import (
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/profile"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// AttributeBoard returns the board whose scores are those of the given board's players with the attribute value,
+// e.g. the players of one country, so that every read of the service can serve filtered leaderboards.
+// The value is matched case-insensitively.
+func (pss *PlayerScoreService) AttributeBoard(b board.Board, a profile.Attribute, value string) (board.Board, error) {
+	value = strings.ToUpper(value)
+	switch a {
+	case profile.AttributeCountry:
+		if !countryPattern.MatchString(value) {
+			return board.Board{}, ErrInvalidCountry
+		}
+	case profile.AttributeRegion:
+		if !regionPattern.MatchString(value) {
+			return board.Board{}, ErrInvalidRegion
+		}
+	default:
+		return board.Board{}, profile.ErrInvalidAttribute
+	}
+
+	b.ID = profile.ScopeID(b.ID, a, value)
+	return b, nil
+}
+
+// resetAttributeScores removes every score of the attribute leaderboards of the board from the database.
+// Their cached leaderboards are dropped with those of the board by dropLeaderboards.
+func (pss *PlayerScoreService) resetAttributeScores(b board.Board) error {
+	for _, a := range profile.Attributes {
+		if err := pss.DBClient.DeleteScoresWithPrefix(profile.ScopePrefix(b.ID, a)); err != nil {
+			pss.Logger.Error("Error deleting attribute leaderboards from DB", zap.String("board_id", b.ID), zap.String("attribute", string(a)), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
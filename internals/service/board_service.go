@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"regexp"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidBoardID      = errors.New("board id must be 1-64 characters of letters, digits, '-' or '_'")                // Returned when a board ID is empty or contains unsupported characters
+	ErrInvalidTieBreak     = errors.New("tie_break must be one of \"earliest\", \"fastest\", \"dense\" or \"standard\"")  // Returned when a board is created with an unknown tie break policy
+	ErrInvalidUpdatePolicy = errors.New("update_policy must be one of \"keep_latest\", \"keep_best\" or \"keep_lowest\"") // Returned when a board is created with an unknown update policy
+)
+
+// boardIDPattern restricts board IDs to characters that are safe in routes and cache keys.
+var boardIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type BoardService struct {
+	BoardClient repositories.IBoardRepository // Interface for board storage operations
+	CacheClient repositories.ICacheRepository // Interface for cache operations
+	CTX         context.Context               // Context for managing request-scoped values
+	Logger      *zap.Logger                   // Logger for structured logging
+}
+
+// NewBoardService initializes a new BoardService with the provided board repository, cache client, context, and logger.
+func NewBoardService(board_client repositories.IBoardRepository, cache_client repositories.ICacheRepository, ctx context.Context, custom_logger *zap.Logger) *BoardService {
+	return &BoardService{
+		BoardClient: board_client,
+		CacheClient: cache_client,
+		CTX:         ctx,
+		Logger:      custom_logger,
+	}
+}
+
+// CreateBoard validates and stores a new board. Empty policies select player_score.DefaultTieBreak and
+// player_score.DefaultUpdatePolicy. The policies cannot be changed later, because cached leaderboards are ordered by them.
+func (bs *BoardService) CreateBoard(boardID, name string, tb player_score.TieBreak, up player_score.UpdatePolicy) (board.Board, error) {
+	bs.Logger.Info("CreateBoard method called", zap.String("board_id", boardID))
+
+	if !boardIDPattern.MatchString(boardID) {
+		return board.Board{}, ErrInvalidBoardID
+	}
+	if tb = tb.OrDefault(); !tb.Valid() {
+		return board.Board{}, ErrInvalidTieBreak
+	}
+	if up = up.OrDefault(); !up.Valid() {
+		return board.Board{}, ErrInvalidUpdatePolicy
+	}
+
+	b := board.Board{ID: boardID, Name: name, TieBreak: tb, UpdatePolicy: up, CreatedAt: time.Now().UTC()}
+	if err := bs.BoardClient.CreateBoard(b); err != nil {
+		bs.Logger.Error("Error creating board", zap.String("board_id", boardID), zap.Error(err))
+		return board.Board{}, err
+	}
+
+	bs.Logger.Info("Board created successfully", zap.String("board_id", boardID))
+	return b, nil
+}
+
+// GetBoards lists every board.
+func (bs *BoardService) GetBoards() ([]board.Board, error) {
+	bs.Logger.Info("GetBoards method called")
+
+	boards, err := bs.BoardClient.GetBoards()
+	if err != nil {
+		bs.Logger.Error("Error retrieving boards from DB", zap.Error(err))
+		return nil, err
+	}
+	return boards, nil
+}
+
+// GetBoard retrieves a single board by its ID.
+func (bs *BoardService) GetBoard(boardID string) (board.Board, error) {
+	return bs.BoardClient.GetBoard(boardID)
+}
+
+// DeleteBoard removes the board and its scores from the database, then drops its cached leaderboards, windows and
+// group leaderboard included, and the cached markers of its players and leaderboards.
+// Rebuilds running concurrently are invalidated first, so they cannot bring the deleted leaderboards back.
+func (bs *BoardService) DeleteBoard(boardID string) error {
+	bs.Logger.Info("DeleteBoard method called", zap.String("board_id", boardID))
+
+	if err := bs.BoardClient.DeleteBoard(boardID); err != nil {
+		bs.Logger.Error("Error deleting board from DB", zap.String("board_id", boardID), zap.Error(err))
+		return err
+	}
+
+	if err := invalidateRebuilds(bs.CacheClient, boardID); err != nil {
+		bs.Logger.Error("Error invalidating leaderboard rebuilds", zap.String("board_id", boardID), zap.Error(err))
+		return err
+	}
+
+	if err := bs.CacheClient.DeleteKey(cachekey.Leaderboard(boardID)); err != nil {
+		bs.Logger.Error("Error deleting board from cache", zap.String("board_id", boardID), zap.Error(err))
+		return err
+	}
+
+	if err := bs.CacheClient.DeleteKey(cachekey.GroupLeaderboard(boardID)); err != nil {
+		bs.Logger.Error("Error deleting board groups from cache", zap.String("board_id", boardID), zap.Error(err))
+		return err
+	}
+
+	// Drop the cached leaderboards of every period of the board's windows as well
+	if err := bs.CacheClient.DeleteKeysWithPrefix(cachekey.Leaderboard(window.ScopePrefix(boardID))); err != nil {
+		bs.Logger.Error("Error deleting board windows from cache", zap.String("board_id", boardID), zap.Error(err))
+		return err
+	}
+
+	// Drop the markers of players without a score and of empty leaderboards, so a board created again under the same
+	// ID does not inherit them
+	for _, prefix := range []string{cachekey.MissingPlayer(boardID, ""), cachekey.EmptyLeaderboard(boardID)} {
+		if err := bs.CacheClient.DeleteKeysWithPrefix(prefix); err != nil {
+			bs.Logger.Error("Error deleting board markers from cache", zap.String("board_id", boardID), zap.String("prefix", prefix), zap.Error(err))
+			return err
+		}
+	}
+
+	bs.Logger.Info("Board deleted successfully", zap.String("board_id", boardID))
+	return nil
+}
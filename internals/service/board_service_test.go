@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories/cachekey"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestDeleteBoardLeavesNothingForABoardCreatedAgain(t *testing.T) {
+	ts := newTestServices(t)
+	boards := NewBoardService(ts.DB, ts.Cache, context.Background(), zap.NewNop())
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	if _, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: "p1", Score: 10}, testOrigin); err != nil {
+		t.Fatalf("AddOrUpdatePlayerScore error = %v", err)
+	}
+	markers := []string{cachekey.MissingPlayer(b.ID, "p2"), cachekey.EmptyLeaderboard(b.ID + "@daily:2026-10-16")}
+	for _, key := range markers {
+		if err := ts.Cache.SetMarker(key, time.Minute); err != nil {
+			t.Fatalf("SetMarker(%q) error = %v", key, err)
+		}
+	}
+
+	if err := boards.DeleteBoard(b.ID); err != nil {
+		t.Fatalf("DeleteBoard error = %v", err)
+	}
+	b = ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+
+	if events, err := ts.Scores.GetScoreHistory(b, "p1", time.Time{}, time.Time{}, 0); err != nil || len(events) != 0 {
+		t.Errorf("history of the new board = %+v, %v, want none", events, err)
+	}
+	for _, key := range markers {
+		if set, _ := ts.Cache.HasMarker(key); set {
+			t.Errorf("marker %q outlived the deleted board", key)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	evictionInterval  = 30 * time.Second // Interval at which the evictor compares the memory used by the cache with its limit
+	evictionBatchSize = 10               // Number of boards evicted before the memory usage is read again
+	evictionLockTTL   = time.Minute      // Longest time a crashed instance keeps the other instances from evicting
+)
+
+type CacheEvictor struct {
+	CacheClient repositories.ICacheRepository // Interface for the cache whose cold boards are evicted
+	MemoryLimit int64                         // Number of bytes the cache may use before boards are evicted
+	CTX         context.Context               // Context stopping the evictor when it is cancelled
+	Logger      *zap.Logger                   // Logger for structured logging
+}
+
+// NewCacheEvictor initializes a new CacheEvictor with the provided cache client, memory limit in bytes, context, and logger.
+func NewCacheEvictor(cache_client repositories.ICacheRepository, memory_limit int64, ctx context.Context, custom_logger *zap.Logger) *CacheEvictor {
+	return &CacheEvictor{
+		CacheClient: cache_client,
+		MemoryLimit: memory_limit,
+		CTX:         ctx,
+		Logger:      custom_logger,
+	}
+}
+
+// Start runs the evictor in the background until the evictor's context is cancelled, checking the memory used by
+// the cache every evictionInterval.
+func (ce *CacheEvictor) Start() {
+	go func() {
+		ticker := time.NewTicker(evictionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ce.CTX.Done():
+				return
+			case <-ticker.C:
+			}
+			ce.evict()
+		}
+	}()
+}
+
+// evict removes the cached leaderboards of the least recently accessed boards, evictionBatchSize boards at a time,
+// until the cache uses no more than MemoryLimit bytes or no board is left. Evicted boards are cold and rebuilt from
+// the database on their next read. Player HASHes are left to their expiry, since they are shared by every board.
+// Only the instance holding the eviction lock in the cache evicts, so the boards are not evicted twice.
+func (ce *CacheEvictor) evict() {
+	token := lockToken()
+	acquired, err := ce.CacheClient.AcquireLock(cachekey.EvictionLock, token, evictionLockTTL)
+	if err != nil || !acquired {
+		return
+	}
+	defer func() {
+		if err := ce.CacheClient.ReleaseLock(cachekey.EvictionLock, token); err != nil {
+			ce.Logger.Error("Error releasing the eviction lock", zap.Error(err))
+		}
+	}()
+
+	for {
+		usage, err := ce.CacheClient.MemoryUsage()
+		if err != nil {
+			ce.Logger.Error("Error reading the memory usage of the cache", zap.Error(err))
+			return
+		}
+		if usage <= ce.MemoryLimit {
+			return
+		}
+
+		boardIDs, err := ce.CacheClient.ColdestBoards(evictionBatchSize)
+		if err != nil {
+			ce.Logger.Error("Error retrieving the coldest boards", zap.Error(err))
+			return
+		}
+		if len(boardIDs) == 0 {
+			ce.Logger.Info("Cache over its memory limit without boards left to evict", zap.Int64("usage", usage), zap.Int64("limit", ce.MemoryLimit))
+			return
+		}
+
+		for _, boardID := range boardIDs {
+			if err := ce.CacheClient.EvictBoard(boardID); err != nil {
+				ce.Logger.Error("Error evicting board from the cache", zap.String("board_id", boardID), zap.Error(err))
+				return
+			}
+		}
+		ce.Logger.Info("Evicted cold boards from the cache", zap.Strings("board_ids", boardIDs), zap.Int64("usage", usage), zap.Int64("limit", ce.MemoryLimit))
+	}
+}
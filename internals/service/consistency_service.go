@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/consistency"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ConsistencyService struct {
+	DBClient    repositories.IDBRepository    // Interface for reading the scores the cache is compared with
+	CacheClient repositories.ICacheRepository // Interface for the cached leaderboards that are checked and repaired
+	CTX         context.Context               // Context for managing request-scoped values
+	Logger      *zap.Logger                   // Logger for structured logging
+}
+
+// NewConsistencyService initializes a new ConsistencyService with the provided database and cache clients, context, and logger.
+func NewConsistencyService(db_client repositories.IDBRepository, cache_client repositories.ICacheRepository, ctx context.Context, custom_logger *zap.Logger) *ConsistencyService {
+	return &ConsistencyService{
+		DBClient:    db_client,
+		CacheClient: cache_client,
+		CTX:         ctx,
+		Logger:      custom_logger,
+	}
+}
+
+// CheckBoard compares the cached all-time leaderboard of the board with the scores in the database and reports the
+// players missing from the cache, the cached entries without a score in the database, and the cached entries ranking
+// a player differently than the database. Names are not compared, since the cached names are shared by every board.
+// An empty cached leaderboard is reported as cold instead, as it is rebuilt on its next read.
+//
+// Both sides are read page by page while scores keep changing, so a player written during the check may be reported
+// although the relay brings the cache up to date moments later. With repair set every reported player is therefore
+// copied from the database again, rather than from the state read during the check.
+func (cs *ConsistencyService) CheckBoard(b board.Board, repair bool) (consistency.Report, error) {
+	cs.Logger.Info("CheckBoard method called", zap.String("board_id", b.ID), zap.Bool("repair", repair))
+
+	tb := b.TieBreak.OrDefault()
+	report := consistency.Report{BoardID: b.ID, Drifts: []consistency.Drift{}, CheckedAt: time.Now().UTC()}
+
+	cached, err := cs.readAll(tb, func(page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+		return cs.CacheClient.GetSetByKey(cachekey.Leaderboard(b.ID), tb, page)
+	})
+	if err != nil {
+		cs.Logger.Error("Error reading the cached leaderboard", zap.String("board_id", b.ID), zap.Error(err))
+		return report, err
+	}
+	report.CacheCount = len(cached)
+
+	stored, err := cs.readAll(tb, func(page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+		return cs.DBClient.GetTopPlayers(b.ID, tb, page)
+	})
+	if err != nil {
+		cs.Logger.Error("Error retrieving records from DB", zap.String("board_id", b.ID), zap.Error(err))
+		return report, err
+	}
+	report.DBCount = len(stored)
+
+	if len(cached) == 0 {
+		report.Cold = true
+		cs.Logger.Info("Cached leaderboard is cold, skipping the comparison", zap.String("board_id", b.ID))
+		return report, nil
+	}
+
+	for playerID, player := range stored {
+		player := player
+		entry, ok := cached[playerID]
+		switch {
+		case !ok:
+			report.Add(consistency.Drift{PlayerID: playerID, Kind: consistency.KindMissing, DB: &player})
+		case tb.SortValue(entry) != tb.SortValue(player):
+			report.Add(consistency.Drift{PlayerID: playerID, Kind: consistency.KindMismatched, DB: &player, Cache: &entry})
+		}
+	}
+	for playerID, entry := range cached {
+		entry := entry
+		if _, ok := stored[playerID]; !ok {
+			report.Add(consistency.Drift{PlayerID: playerID, Kind: consistency.KindExtra, Cache: &entry})
+		}
+	}
+
+	sort.Slice(report.Drifts, func(i, j int) bool { return report.Drifts[i].PlayerID < report.Drifts[j].PlayerID })
+
+	cs.Logger.Info("Cache consistency checked", zap.String("board_id", b.ID), zap.Int("missing", report.Missing), zap.Int("extra", report.Extra), zap.Int("mismatched", report.Mismatched))
+	if !repair {
+		return report, nil
+	}
+
+	for _, drift := range report.Drifts {
+		if err := copyPlayerScore(cs.DBClient, cs.CacheClient, b.ID, tb, drift.PlayerID); err != nil {
+			cs.Logger.Error("Error repairing cached leaderboard entry", zap.String("board_id", b.ID), zap.String("player_id", drift.PlayerID), zap.Error(err))
+			return report, err
+		}
+		report.Repaired++
+	}
+
+	cs.Logger.Info("Cached leaderboard repaired", zap.String("board_id", b.ID), zap.Int("repaired", report.Repaired))
+	return report, nil
+}
+
+// readAll reads every entry of a leaderboard through fetch in pages of cacheWarmBatchSize and returns them by player ID.
+func (cs *ConsistencyService) readAll(tb player_score.TieBreak, fetch func(page player_score.PageRequest) ([]player_score.PlayerScore, error)) (map[string]player_score.PlayerScore, error) {
+	entries := make(map[string]player_score.PlayerScore)
+	page := player_score.PageRequest{Limit: cacheWarmBatchSize}
+	for {
+		players, err := fetch(page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, player := range players {
+			entries[player.PlayerID] = player
+		}
+
+		if int64(len(players)) < page.Limit {
+			return entries, nil
+		}
+		cursor := player_score.CursorOf(players[len(players)-1])
+		page.After = &cursor
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/group"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidGroupID     = errors.New("group id must be 1-64 characters of letters, digits, '-' or '_'")                             // Returned when a group ID is empty or contains unsupported characters
+	ErrInvalidAggregation = errors.New("aggregation must be one of \"sum\", \"avg\" or \"top_k\", and top_k needs a k of at least 1") // Returned when a group is created with an unknown aggregation
+)
+
+type GroupService struct {
+	GroupClient repositories.IGroupRepository // Interface for group storage operations
+	DBClient    repositories.IDBRepository    // Interface for reading the scores of the members
+	CacheClient repositories.ICacheRepository // Interface for cache operations
+	CTX         context.Context               // Context for managing request-scoped values
+	Logger      *zap.Logger                   // Logger for structured logging
+}
+
+// NewGroupService initializes a new GroupService with the provided group repository, database and cache clients, context, and logger.
+func NewGroupService(group_client repositories.IGroupRepository, db_client repositories.IDBRepository, cache_client repositories.ICacheRepository, ctx context.Context, custom_logger *zap.Logger) *GroupService {
+	return &GroupService{
+		GroupClient: group_client,
+		DBClient:    db_client,
+		CacheClient: cache_client,
+		CTX:         ctx,
+		Logger:      custom_logger,
+	}
+}
+
+// CreateGroup validates and stores a new group. An empty aggregation selects group.DefaultAggregation.
+func (gs *GroupService) CreateGroup(groupID, name string, aggregation group.Aggregation, k int) (group.Group, error) {
+	gs.Logger.Info("CreateGroup method called", zap.String("group_id", groupID))
+
+	if !boardIDPattern.MatchString(groupID) {
+		return group.Group{}, ErrInvalidGroupID
+	}
+	if aggregation = aggregation.OrDefault(); !aggregation.Valid() || (aggregation == group.AggregateTopK && k < 1) {
+		return group.Group{}, ErrInvalidAggregation
+	}
+	if aggregation != group.AggregateTopK {
+		k = 0
+	}
+
+	g := group.Group{ID: groupID, Name: name, Aggregation: aggregation, K: k, CreatedAt: time.Now().UTC()}
+	if err := gs.GroupClient.CreateGroup(g); err != nil {
+		gs.Logger.Error("Error creating group", zap.String("group_id", groupID), zap.Error(err))
+		return group.Group{}, err
+	}
+
+	gs.Logger.Info("Group created successfully", zap.String("group_id", groupID))
+	return g, nil
+}
+
+// GetGroups lists every group.
+func (gs *GroupService) GetGroups() ([]group.Group, error) {
+	gs.Logger.Info("GetGroups method called")
+
+	groups, err := gs.GroupClient.GetGroups()
+	if err != nil {
+		gs.Logger.Error("Error retrieving groups from DB", zap.Error(err))
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetGroup retrieves a single group by its ID.
+func (gs *GroupService) GetGroup(groupID string) (group.Group, error) {
+	return gs.GroupClient.GetGroup(groupID)
+}
+
+// DeleteGroup removes the group and its memberships, then drops the cached group leaderboards.
+func (gs *GroupService) DeleteGroup(groupID string) error {
+	gs.Logger.Info("DeleteGroup method called", zap.String("group_id", groupID))
+
+	if err := gs.GroupClient.DeleteGroup(groupID); err != nil {
+		gs.Logger.Error("Error deleting group from DB", zap.String("group_id", groupID), zap.Error(err))
+		return err
+	}
+	return gs.invalidateLeaderboards()
+}
+
+// GetMembers returns the IDs of the members of a group.
+func (gs *GroupService) GetMembers(groupID string) ([]string, error) {
+	gs.Logger.Info("GetMembers method called", zap.String("group_id", groupID))
+
+	if _, err := gs.GroupClient.GetGroup(groupID); err != nil {
+		return nil, err
+	}
+	return gs.GroupClient.GetGroupMembers(groupID)
+}
+
+// AddMember adds a player to a group. The cached group leaderboards of every board are dropped,
+// because the player may have scores on any of them, and are rebuilt on their next read.
+func (gs *GroupService) AddMember(groupID, playerID string) error {
+	gs.Logger.Info("AddMember method called", zap.String("group_id", groupID), zap.String("player_id", playerID))
+
+	if _, err := gs.GroupClient.GetGroup(groupID); err != nil {
+		return err
+	}
+	if err := gs.GroupClient.AddGroupMember(groupID, playerID); err != nil {
+		gs.Logger.Error("Error adding group member", zap.String("group_id", groupID), zap.String("player_id", playerID), zap.Error(err))
+		return err
+	}
+	return gs.invalidateLeaderboards()
+}
+
+// RemoveMember removes a player from a group and drops the cached group leaderboards like AddMember.
+func (gs *GroupService) RemoveMember(groupID, playerID string) error {
+	gs.Logger.Info("RemoveMember method called", zap.String("group_id", groupID), zap.String("player_id", playerID))
+
+	if _, err := gs.GroupClient.GetGroup(groupID); err != nil {
+		return err
+	}
+	if err := gs.GroupClient.RemoveGroupMember(groupID, playerID); err != nil {
+		gs.Logger.Error("Error removing group member", zap.String("group_id", groupID), zap.String("player_id", playerID), zap.Error(err))
+		return err
+	}
+	return gs.invalidateLeaderboards()
+}
+
+// invalidateLeaderboards drops the cached group leaderboards of every board.
+func (gs *GroupService) invalidateLeaderboards() error {
+	if err := gs.CacheClient.DeleteKeysMatching(cachekey.GroupLeaderboardPattern); err != nil {
+		gs.Logger.Error("Error deleting group leaderboards from cache", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// RefreshPlayerGroups recomputes the scores of every group of the player on the board's cached group leaderboard.
+// It is called after the player's score on the board changed. A cold group leaderboard is left alone,
+// since it is rebuilt completely on its next read.
+func (gs *GroupService) RefreshPlayerGroups(boardID, playerID string) {
+	key := cachekey.GroupLeaderboard(boardID)
+	if size, err := gs.CacheClient.GetSetSize(key); err != nil || size == 0 {
+		return
+	}
+
+	groupIDs, err := gs.GroupClient.GetPlayerGroups(playerID)
+	if err != nil {
+		gs.Logger.Error("Error retrieving player groups from DB", zap.String("player_id", playerID), zap.Error(err))
+		return
+	}
+
+	for _, groupID := range groupIDs {
+		g, err := gs.GroupClient.GetGroup(groupID)
+		if err == nil {
+			var score float64
+			var scored bool
+			if score, scored, err = gs.groupScore(boardID, g); err == nil {
+				if scored {
+					err = gs.CacheClient.SetMemberScore(key, g.ID, score)
+				} else {
+					err = gs.CacheClient.RemoveMember(key, g.ID)
+				}
+			}
+		}
+		if err != nil {
+			gs.Logger.Error("Error refreshing group score", zap.String("board_id", boardID), zap.String("group_id", groupID), zap.Error(err))
+		}
+	}
+}
+
+// groupScore aggregates the scores of the group's members on the board.
+// It reports false when none of the members has a score on the board.
+func (gs *GroupService) groupScore(boardID string, g group.Group) (float64, bool, error) {
+	members, err := gs.GroupClient.GetGroupMembers(g.ID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	players, err := gs.DBClient.GetPlayerScores(boardID, members)
+	if err != nil || len(players) == 0 {
+		return 0, false, err
+	}
+
+	scores := make([]int, len(players))
+	for i, player := range players {
+		scores[i] = player.Score
+	}
+	return g.Aggregate(scores), true, nil
+}
+
+// GetGroupLeaderboard returns a page of the groups ranked by the aggregated scores of their members on the board,
+// together with the number of ranked groups. Groups without any scored member are left out, and groups with equal
+// scores are ordered by their ID. A cold cache is rebuilt from the database first.
+func (gs *GroupService) GetGroupLeaderboard(b board.Board, offset, limit int64) ([]group.Standing, int64, error) {
+	gs.Logger.Info("GetGroupLeaderboard method called", zap.String("board_id", b.ID), zap.Int64("offset", offset), zap.Int64("limit", limit))
+
+	groups, err := gs.GroupClient.GetGroups()
+	if err != nil {
+		gs.Logger.Error("Error retrieving groups from DB", zap.Error(err))
+		return nil, 0, err
+	}
+	names := make(map[string]string, len(groups))
+	for _, g := range groups {
+		names[g.ID] = g.Name
+	}
+
+	// Attempt to read the group leaderboard from cache
+	key := cachekey.GroupLeaderboard(b.ID)
+	total, err := gs.CacheClient.GetSetSize(key)
+	if err == nil && total > 0 {
+		var members []repositories.MemberScore
+		if members, err = gs.CacheClient.GetMemberScores(key, offset, limit); err == nil {
+			standings := make([]group.Standing, len(members))
+			for i, member := range members {
+				standings[i] = group.Standing{GroupID: member.Member, Name: names[member.Member], Score: member.Score, Rank: offset + int64(i) + 1}
+			}
+			gs.Logger.Info("Cached response provided", zap.Int("count", len(standings)))
+			return standings, total, nil
+		}
+	}
+	if err != nil {
+		gs.Logger.Error("Error retrieving group leaderboard from Cache", zap.Error(err))
+	}
+
+	// Cache miss, compute every group's score from the database and cache the result
+	gs.Logger.Info("Cache miss, computing group leaderboard from DB", zap.String("board_id", b.ID))
+
+	standings := make([]group.Standing, 0, len(groups))
+	for _, g := range groups {
+		score, scored, err := gs.groupScore(b.ID, g)
+		if err != nil {
+			gs.Logger.Error("Error computing group score", zap.String("group_id", g.ID), zap.Error(err))
+			return nil, 0, err
+		}
+		if scored {
+			standings = append(standings, group.Standing{GroupID: g.ID, Name: g.Name, Score: score})
+		}
+	}
+
+	// Same order as ZREVRANGE: highest score first, equal scores by descending member
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Score != standings[j].Score {
+			return standings[i].Score > standings[j].Score
+		}
+		return standings[i].GroupID > standings[j].GroupID
+	})
+	for i := range standings {
+		standings[i].Rank = int64(i) + 1
+		if err := gs.CacheClient.SetMemberScore(key, standings[i].GroupID, standings[i].Score); err != nil {
+			gs.Logger.Error("Error caching group score", zap.String("group_id", standings[i].GroupID), zap.Error(err))
+		}
+	}
+
+	total = int64(len(standings))
+	if offset > total {
+		offset = total
+	}
+	standings = standings[offset:]
+	if limit > 0 && limit < int64(len(standings)) {
+		standings = standings[:limit]
+	}
+	return standings, total, nil
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/outbox"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	relayInterval  = time.Second      // Interval at which the relay polls the outbox when it is not notified of a change
+	relayBatchSize = 100              // Number of outbox entries applied per batch
+	relayLockTTL   = 30 * time.Second // Longest time a crashed instance keeps the other instances from relaying
+	copyAttempts   = 3                // Number of times a score is copied to the cache before a copy overtaken by newer writes is given up
+)
+
+// errCopyOvertaken is returned when the score of a player kept changing while it was copied to the cache, so the
+// cached leaderboard may hold an older score than the database.
+var errCopyOvertaken = errors.New("the score changed again while it was copied to the cache")
+
+type OutboxRelay struct {
+	OutboxClient repositories.IOutboxRepository // Interface for reading and settling the outbox entries
+	DBClient     repositories.IDBRepository     // Interface for reading the current scores the entries refer to
+	BoardClient  repositories.IBoardRepository  // Interface for reading the tie break policy of the boards
+	CacheClient  repositories.ICacheRepository  // Interface for the cached leaderboards the entries are applied to
+	CTX          context.Context                // Context stopping the relay when it is cancelled
+	Logger       *zap.Logger                    // Logger for structured logging
+	notify       chan struct{}                  // Wakes the relay up after a score change
+}
+
+// NewOutboxRelay initializes a new OutboxRelay with the provided outbox, database, board and cache clients, context, and logger.
+func NewOutboxRelay(outbox_client repositories.IOutboxRepository, db_client repositories.IDBRepository, board_client repositories.IBoardRepository, cache_client repositories.ICacheRepository, ctx context.Context, custom_logger *zap.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		OutboxClient: outbox_client,
+		DBClient:     db_client,
+		BoardClient:  board_client,
+		CacheClient:  cache_client,
+		CTX:          ctx,
+		Logger:       custom_logger,
+		notify:       make(chan struct{}, 1),
+	}
+}
+
+// Start runs the relay in the background until the relay's context is cancelled.
+// The relay applies the pending outbox entries whenever it is notified and at least every relayInterval.
+func (or *OutboxRelay) Start() {
+	go func() {
+		ticker := time.NewTicker(relayInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-or.CTX.Done():
+				return
+			case <-ticker.C:
+			case <-or.notify:
+			}
+			or.relayPending()
+		}
+	}()
+}
+
+// Notify wakes the relay up to apply a new outbox entry. It never blocks, notifications arriving while the relay is
+// busy are merged into one.
+func (or *OutboxRelay) Notify() {
+	select {
+	case or.notify <- struct{}{}:
+	default:
+	}
+}
+
+// relayPending applies the due outbox entries batch by batch until none are left. Only the instance holding the relay
+// lock in the cache relays, so the entries of a player are never applied by two instances at the same time. The lock is
+// renewed while the batches are applied, see holdLock, and the relay stops before the next batch once it was lost.
+func (or *OutboxRelay) relayPending() {
+	token := lockToken()
+	acquired, err := or.CacheClient.AcquireLock(cachekey.OutboxRelayLock, token, relayLockTTL)
+	if err != nil || !acquired {
+		return
+	}
+	held, stop := holdLock(or.CTX, or.CacheClient, or.Logger, cachekey.OutboxRelayLock, token, relayLockTTL)
+	defer func() {
+		stop()
+		if err := or.CacheClient.ReleaseLock(cachekey.OutboxRelayLock, token); err != nil {
+			or.Logger.Error("Error releasing the outbox relay lock", zap.Error(err))
+		}
+	}()
+
+	for held.Err() == nil {
+		entries, err := or.OutboxClient.GetPendingEntries(time.Now().UTC(), relayBatchSize)
+		if err != nil {
+			or.Logger.Error("Error retrieving pending outbox entries", zap.Error(err))
+			return
+		}
+		if len(entries) == 0 || !or.applyBatch(entries) || len(entries) < relayBatchSize {
+			return
+		}
+	}
+	or.Logger.Info("Outbox relay lock lost, leaving the remaining entries to its holder")
+}
+
+// applyBatch applies a batch of outbox entries to the cache and reports whether the applied entries were settled.
+// The entries of one player in one scope are applied together, in the order they were written, by copying the player's
+// current score from the database. When that fails every entry of the player is retried later, so the player's changes
+// keep their order. Entries failing MaxAttempts times are moved to the dead letters.
+func (or *OutboxRelay) applyBatch(entries []outbox.Entry) bool {
+	byKey := make(map[string][]outbox.Entry)
+	var keys []string
+	for _, entry := range entries {
+		if _, ok := byKey[entry.Key()]; !ok {
+			keys = append(keys, entry.Key())
+		}
+		byKey[entry.Key()] = append(byKey[entry.Key()], entry)
+	}
+
+	boards := make(map[string]board.Board)
+	var completed []string
+	for _, key := range keys {
+		group := byKey[key]
+		err := or.apply(group[0].ScopeID, group[0].PlayerID, boards)
+		if err == nil {
+			for _, entry := range group {
+				completed = append(completed, entry.ID)
+			}
+			continue
+		}
+
+		or.Logger.Error("Error applying outbox entries to the cache", zap.String("scope_id", group[0].ScopeID), zap.String("player_id", group[0].PlayerID), zap.Error(err))
+		for _, entry := range group {
+			entry.Fail(err, time.Now().UTC())
+			if entry.Status == outbox.StatusDead {
+				or.Logger.Error("Outbox entry moved to the dead letters", zap.String("entry_id", entry.ID), zap.Int("attempts", entry.Attempts))
+			}
+			if err := or.OutboxClient.UpdateEntry(entry); err != nil {
+				or.Logger.Error("Error updating outbox entry", zap.String("entry_id", entry.ID), zap.Error(err))
+			}
+		}
+	}
+
+	if len(completed) == 0 {
+		return true
+	}
+	if err := or.OutboxClient.CompleteEntries(completed, time.Now().UTC()); err != nil {
+		or.Logger.Error("Error completing outbox entries", zap.Int("count", len(completed)), zap.Error(err))
+		return false
+	}
+	or.Logger.Info("Outbox entries applied to the cache", zap.Int("count", len(completed)))
+	return true
+}
+
+// apply copies the player's current score in the scope to the cache with copyPlayerScore. Scopes of deleted boards are skipped.
+// The boards map caches the boards read during one batch.
+func (or *OutboxRelay) apply(scopeID, playerID string, boards map[string]board.Board) error {
+	boardID, _ := window.SplitScopeID(scopeID)
+	b, ok := boards[boardID]
+	if !ok {
+		var err error
+		b, err = or.BoardClient.GetBoard(boardID)
+		if errors.Is(err, repositories.ErrBoardNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		boards[boardID] = b
+	}
+
+	return copyPlayerScore(or.DBClient, or.CacheClient, scopeID, b.TieBreak.OrDefault(), playerID)
+}
+
+// copyPlayerScore copies the player's current score in the scope from the database to the cached leaderboard of the
+// scope, or removes the player from it when the score no longer exists, see copyPlayerScores.
+func copyPlayerScore(db repositories.IDBRepository, cache repositories.ICacheRepository, scopeID string, tb player_score.TieBreak, playerID string) error {
+	return copyPlayerScores(db, cache, scopeID, tb, []string{playerID})
+}
+
+// copyPlayerScores copies the current scores of the players in the scope from the database to the cached leaderboard
+// of the scope, and removes the players whose score no longer exists from it. Copies of different writers are not
+// ordered, so a copy read before a newer write may reach the cache after the copy of that write. Every score is
+// therefore read again once it was written to the cache, and copied again when it changed in the meantime: a score
+// still current after it was cached was either cached last or will be overwritten by the copy of the newer write.
+// It returns errCopyOvertaken when the scores kept changing for copyAttempts copies.
+func copyPlayerScores(db repositories.IDBRepository, cache repositories.ICacheRepository, scopeID string, tb player_score.TieBreak, playerIDs []string) error {
+	key := cachekey.Leaderboard(scopeID)
+	copied, err := readPlayerScores(db, scopeID, playerIDs)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; len(playerIDs) > 0; attempt++ {
+		for _, playerID := range playerIDs {
+			if player, ok := copied[playerID]; ok {
+				err = cache.UpdatePlayerCache(key, tb, player_score.UpdateKeepLatest, player)
+			} else {
+				err = cache.RemoveMember(key, playerID)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		current, err := readPlayerScores(db, scopeID, playerIDs)
+		if err != nil {
+			return err
+		}
+		var changed []string
+		for _, playerID := range playerIDs {
+			before, had := copied[playerID]
+			after, has := current[playerID]
+			if had != has || (has && !sameScore(before, after)) {
+				changed = append(changed, playerID)
+			}
+		}
+		if len(changed) > 0 && attempt == copyAttempts {
+			return errCopyOvertaken
+		}
+		playerIDs, copied = changed, current
+	}
+	return nil
+}
+
+// readPlayerScores reads the scores of the players in the scope from the database, by player ID.
+func readPlayerScores(db repositories.IDBRepository, scopeID string, playerIDs []string) (map[string]player_score.PlayerScore, error) {
+	players, err := db.GetPlayerScores(scopeID, playerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]player_score.PlayerScore, len(players))
+	for _, player := range players {
+		byID[player.PlayerID] = player
+	}
+	return byID, nil
+}
+
+// sameScore reports whether two reads of a player's score hold the same cached fields.
+func sameScore(a, b player_score.PlayerScore) bool {
+	return a.Score == b.Score && a.AchievedAt.Equal(b.AchievedAt) && a.CompletionTime == b.CompletionTime && a.PlayerName == b.PlayerName
+}
+
+// GetDeadEntries returns a page of the outbox entries that were given up after MaxAttempts failed attempts, oldest first.
+func (or *OutboxRelay) GetDeadEntries(offset, limit int64) ([]outbox.Entry, error) {
+	or.Logger.Info("GetDeadEntries method called", zap.Int64("offset", offset), zap.Int64("limit", limit))
+
+	entries, err := or.OutboxClient.GetDeadEntries(offset, limit)
+	if err != nil {
+		or.Logger.Error("Error retrieving dead outbox entries", zap.Error(err))
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RetryDeadEntry moves a dead outbox entry back to the pending entries and wakes the relay up to apply it.
+// It returns repositories.ErrOutboxEntryNotFound if there is no dead entry with the ID.
+func (or *OutboxRelay) RetryDeadEntry(id string) error {
+	or.Logger.Info("RetryDeadEntry method called", zap.String("entry_id", id))
+
+	if err := or.OutboxClient.RetryDeadEntry(id, time.Now().UTC()); err != nil {
+		if !errors.Is(err, repositories.ErrOutboxEntryNotFound) {
+			or.Logger.Error("Error retrying dead outbox entry", zap.String("entry_id", id), zap.Error(err))
+		}
+		return err
+	}
+
+	or.Notify()
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"errors"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"testing"
+	"time"
+)
+
+// racingDB is a database whose score reads are followed by a concurrent write, the way another request's write lands
+// between the read of a copy and its write to the cache.
+type racingDB struct {
+	*repositories.MemoryDBClient
+	reads int                          // Number of score reads so far
+	after func(read int, db *racingDB) // Called after every score read with its number, starting at 1
+}
+
+// GetPlayerScores reads the scores and then runs the concurrent write of the read.
+func (db *racingDB) GetPlayerScores(boardID string, playerIDs []string) ([]player_score.PlayerScore, error) {
+	players, err := db.MemoryDBClient.GetPlayerScores(boardID, playerIDs)
+	db.reads++
+	if db.after != nil {
+		db.after(db.reads, db)
+	}
+	return players, err
+}
+
+func TestCopyPlayerScoreNeverLeavesAnOlderScoreBehind(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	write := func(score int) {
+		if _, err := ts.DB.UpdateOrInsertPlayerScore(b.ID, player_score.UpdateKeepLatest, player_score.PlayerScore{PlayerID: "p1", Score: score, AchievedAt: time.Now().UTC()}, nil); err != nil {
+			t.Fatalf("UpdateOrInsertPlayerScore error = %v", err)
+		}
+	}
+	write(10)
+	ts.warm(t, b)
+	write(20)
+
+	// Right after the copy read 20, another request writes 30 and its copy reaches the cache first
+	db := &racingDB{MemoryDBClient: ts.DB, after: func(read int, db *racingDB) {
+		if read == 1 {
+			write(30)
+			if err := copyPlayerScore(db.MemoryDBClient, ts.Cache, b.ID, b.TieBreak, "p1"); err != nil {
+				t.Fatalf("copyPlayerScore error = %v", err)
+			}
+		}
+	}}
+	if err := copyPlayerScore(db, ts.Cache, b.ID, b.TieBreak, "p1"); err != nil {
+		t.Fatalf("copyPlayerScore error = %v", err)
+	}
+
+	if score, _ := ts.cachedScore(t, b.ID, "p1"); score != 30 {
+		t.Errorf("cached score = %d, want the newest score 30", score)
+	}
+}
+
+func TestCopyPlayerScoreRemovesDeletedScores(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	if err := ts.Cache.AddToSet(cachekey.Leaderboard(b.ID), b.TieBreak, []player_score.PlayerScore{{PlayerID: "p1", Score: 10}, {PlayerID: "p2", Score: 5}}); err != nil {
+		t.Fatalf("AddToSet error = %v", err)
+	}
+
+	if err := copyPlayerScore(ts.DB, ts.Cache, b.ID, b.TieBreak, "p1"); err != nil {
+		t.Fatalf("copyPlayerScore error = %v", err)
+	}
+	if _, cached := ts.cachedScore(t, b.ID, "p1"); cached {
+		t.Errorf("player without a stored score is still cached")
+	}
+}
+
+func TestCacheScoreDropsTheLeaderboardWhenOvertaken(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	if _, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: "p1", Score: 10}, testOrigin); err != nil {
+		t.Fatalf("AddOrUpdatePlayerScore error = %v", err)
+	}
+	ts.warm(t, b)
+
+	// Every read of the score is followed by a newer write, so no copy can be confirmed
+	score := 10
+	ts.Scores.DBClient = &racingDB{MemoryDBClient: ts.DB, after: func(int, *racingDB) {
+		score++
+		if _, err := ts.DB.UpdateOrInsertPlayerScore(b.ID, player_score.UpdateKeepLatest, player_score.PlayerScore{PlayerID: "p1", Score: score}, nil); err != nil {
+			t.Fatalf("UpdateOrInsertPlayerScore error = %v", err)
+		}
+	}}
+	ts.Scores.cacheScore(b, "p1")
+
+	if size, _ := ts.Cache.GetSetSize(cachekey.Leaderboard(b.ID)); size != 0 {
+		t.Errorf("cached leaderboard size = %d, want it dropped", size)
+	}
+}
+
+// failingCache is a cache failing every write of one player.
+type failingCache struct {
+	*repositories.MemoryCacheClient
+	playerID string // Player whose writes fail
+}
+
+// UpdatePlayerCache fails for the player and writes every other player.
+func (c *failingCache) UpdatePlayerCache(key string, tb player_score.TieBreak, up player_score.UpdatePolicy, player player_score.PlayerScore) error {
+	if player.PlayerID == c.playerID {
+		return errors.New("cache unavailable")
+	}
+	return c.MemoryCacheClient.UpdatePlayerCache(key, tb, up, player)
+}
+
+// writeScores stores the scores in the database without caching them, leaving them to the relay.
+func (ts testServices) writeScores(t *testing.T, boardID string, players ...player_score.PlayerScore) {
+	t.Helper()
+	for _, player := range players {
+		if _, err := ts.DB.UpdateOrInsertPlayerScore(boardID, player_score.UpdateKeepLatest, player, nil); err != nil {
+			t.Fatalf("UpdateOrInsertPlayerScore error = %v", err)
+		}
+	}
+}
+
+func TestRelayAppliesTheLatestScoreOfEveryPlayer(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	ts.writeScores(t, b.ID, player_score.PlayerScore{PlayerID: "p0", Score: 1})
+	ts.warm(t, b)
+
+	ts.writeScores(t, b.ID,
+		player_score.PlayerScore{PlayerID: "p1", Score: 10},
+		player_score.PlayerScore{PlayerID: "p2", Score: 5},
+		player_score.PlayerScore{PlayerID: "p1", Score: 20},
+	)
+	ts.Relay.relayPending()
+
+	for playerID, want := range map[string]int{"p0": 1, "p1": 20, "p2": 5} {
+		if score, cached := ts.cachedScore(t, b.ID, playerID); !cached || score != want {
+			t.Errorf("cached score of %q = %d, %v, want %d", playerID, score, cached, want)
+		}
+	}
+	if pending, _ := ts.DB.GetPendingEntries(time.Now().Add(time.Hour), 100); len(pending) != 0 {
+		t.Errorf("pending entries = %v, want none", pending)
+	}
+}
+
+func TestRelayRetriesTheEntriesOfAFailedPlayerTogether(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	ts.writeScores(t, b.ID, player_score.PlayerScore{PlayerID: "p0", Score: 1})
+	ts.warm(t, b)
+
+	ts.writeScores(t, b.ID,
+		player_score.PlayerScore{PlayerID: "p1", Score: 10},
+		player_score.PlayerScore{PlayerID: "p2", Score: 5},
+		player_score.PlayerScore{PlayerID: "p1", Score: 20},
+	)
+	ts.Relay.CacheClient = &failingCache{MemoryCacheClient: ts.Cache, playerID: "p1"}
+	ts.Relay.relayPending()
+
+	if score, cached := ts.cachedScore(t, b.ID, "p2"); !cached || score != 5 {
+		t.Errorf("cached score of %q = %d, %v, want 5", "p2", score, cached)
+	}
+	if pending, _ := ts.DB.GetPendingEntries(time.Now(), 100); len(pending) != 0 {
+		t.Errorf("entries due right after the failure = %v, want them delayed", pending)
+	}
+
+	retried, err := ts.DB.GetPendingEntries(time.Now().Add(time.Hour), 100)
+	if err != nil {
+		t.Fatalf("GetPendingEntries error = %v", err)
+	}
+	if len(retried) != 2 {
+		t.Fatalf("retried entries = %v, want both entries of p1", retried)
+	}
+	for i, entry := range retried {
+		if entry.PlayerID != "p1" || entry.Attempts != 1 || entry.LastError == "" {
+			t.Errorf("retried entry %d = %+v, want a failed attempt of p1", i, entry)
+		}
+	}
+	if retried[0].ID > retried[1].ID {
+		t.Errorf("retried entries %q and %q are out of their write order", retried[0].ID, retried[1].ID)
+	}
+}
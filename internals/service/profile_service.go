@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"quiz/internals/domain/profile"
+	"quiz/internals/repositories"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidPlayerID    = errors.New("player_id must be 1-128 characters")                            // Returned when a profile is created without a player ID
+	ErrInvalidDisplayName = errors.New("display_name must be 1-64 characters")                          // Returned when a display name is empty or too long
+	ErrInvalidAvatarURL   = errors.New("avatar_url must be an absolute http or https URL")              // Returned when an avatar URL cannot be shown by clients
+	ErrInvalidCountry     = errors.New("country must be a two-letter ISO 3166-1 alpha-2 code")          // Returned when a country is not a two-letter code
+	ErrInvalidRegion      = errors.New("region must be 1-32 characters of letters, digits, '-' or '_'") // Returned when a region contains unsupported characters
+)
+
+var (
+	countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)         // Matches ISO 3166-1 alpha-2 country codes after they are upper-cased
+	regionPattern  = regexp.MustCompile(`^[A-Z0-9_-]{1,32}$`) // Matches region codes after they are upper-cased, they are used in scope IDs
+)
+
+const (
+	maxPlayerIDLength    = 128 // Longest player ID a profile may be created for
+	maxDisplayNameLength = 64  // Longest display name in characters
+)
+
+type ProfileService struct {
+	ProfileClient repositories.IProfileRepository // Interface for profile storage operations, moving scores between attribute leaderboards
+	Outbox        *OutboxRelay                    // Relay applying the moved scores to the cached attribute leaderboards
+	CTX           context.Context                 // Context for managing request-scoped values
+	Logger        *zap.Logger                     // Logger for structured logging
+}
+
+// NewProfileService initializes a new ProfileService with the provided profile repository, outbox relay, context, and logger.
+func NewProfileService(profile_client repositories.IProfileRepository, outbox_relay *OutboxRelay, ctx context.Context, custom_logger *zap.Logger) *ProfileService {
+	return &ProfileService{
+		ProfileClient: profile_client,
+		Outbox:        outbox_relay,
+		CTX:           ctx,
+		Logger:        custom_logger,
+	}
+}
+
+// CreateProfile validates and stores the profile of a player. The country and region codes are stored upper-cased.
+// Scores the player already has are added to the leaderboards of their country and region in the same transaction.
+func (ps *ProfileService) CreateProfile(p profile.Profile) (profile.Profile, error) {
+	ps.Logger.Info("CreateProfile method called", zap.String("player_id", p.PlayerID))
+
+	if p.PlayerID == "" || len(p.PlayerID) > maxPlayerIDLength {
+		return profile.Profile{}, ErrInvalidPlayerID
+	}
+	now := time.Now().UTC()
+	p = profile.Profile{PlayerID: p.PlayerID, DisplayName: p.DisplayName, AvatarURL: p.AvatarURL, Country: strings.ToUpper(p.Country), Region: strings.ToUpper(p.Region), CreatedAt: now, UpdatedAt: now}
+	if err := validateProfile(profile.Update{DisplayName: &p.DisplayName, AvatarURL: &p.AvatarURL, Country: &p.Country, Region: &p.Region}); err != nil {
+		return profile.Profile{}, err
+	}
+
+	if err := ps.ProfileClient.CreateProfile(p); err != nil {
+		ps.Logger.Error("Error creating profile", zap.String("player_id", p.PlayerID), zap.Error(err))
+		return profile.Profile{}, err
+	}
+
+	ps.Logger.Info("Profile created successfully", zap.String("player_id", p.PlayerID))
+	ps.Outbox.Notify()
+	return p, nil
+}
+
+// GetProfile retrieves the profile of a player.
+func (ps *ProfileService) GetProfile(playerID string) (profile.Profile, error) {
+	return ps.ProfileClient.GetProfile(playerID)
+}
+
+// UpdateProfile validates and applies the update to the profile of a player and returns the updated profile.
+// The new display name shows up on every leaderboard at once, because leaderboards join names from the profiles.
+// When the country or region changes, the player's scores move to the leaderboards of the new values in the same
+// transaction, so score writes running at the same time never land on the leaderboards of the old values.
+func (ps *ProfileService) UpdateProfile(playerID string, update profile.Update) (profile.Profile, error) {
+	ps.Logger.Info("UpdateProfile method called", zap.String("player_id", playerID))
+
+	if update.Country != nil {
+		country := strings.ToUpper(*update.Country)
+		update.Country = &country
+	}
+	if update.Region != nil {
+		region := strings.ToUpper(*update.Region)
+		update.Region = &region
+	}
+
+	if err := validateProfile(update); err != nil {
+		return profile.Profile{}, err
+	}
+
+	p, err := ps.ProfileClient.UpdateProfile(playerID, update, time.Now().UTC())
+	if err != nil {
+		ps.Logger.Error("Error updating profile", zap.String("player_id", playerID), zap.Error(err))
+		return profile.Profile{}, err
+	}
+
+	ps.Logger.Info("Profile updated successfully", zap.String("player_id", playerID))
+	ps.Outbox.Notify()
+	return p, nil
+}
+
+// DeleteProfile removes the profile of a player. Their scores are kept and shown with the name they were submitted with,
+// but they leave the leaderboards of their country and region.
+func (ps *ProfileService) DeleteProfile(playerID string) error {
+	ps.Logger.Info("DeleteProfile method called", zap.String("player_id", playerID))
+
+	if err := ps.ProfileClient.DeleteProfile(playerID); err != nil {
+		ps.Logger.Error("Error deleting profile", zap.String("player_id", playerID), zap.Error(err))
+		return err
+	}
+	ps.Outbox.Notify()
+	return nil
+}
+
+// validateProfile checks the display name, avatar URL, country and region set in the update, fields left nil are not checked.
+// An empty avatar URL, country or region is valid and clears the field.
+func validateProfile(update profile.Update) error {
+	if name := update.DisplayName; name != nil && (*name == "" || utf8.RuneCountInString(*name) > maxDisplayNameLength) {
+		return ErrInvalidDisplayName
+	}
+	if avatar := update.AvatarURL; avatar != nil && *avatar != "" {
+		u, err := url.Parse(*avatar)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidAvatarURL
+		}
+	}
+	if country := update.Country; country != nil && *country != "" && !countryPattern.MatchString(*country) {
+		return ErrInvalidCountry
+	}
+	if region := update.Region; region != nil && *region != "" && !regionPattern.MatchString(*region) {
+		return ErrInvalidRegion
+	}
+	return nil
+}
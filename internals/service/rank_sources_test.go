@@ -0,0 +1,97 @@
+package service
+
+import (
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// rankingPlayers returns players with repeated scores and distinct tie values, sorted with tb.Before.
+func rankingPlayers(tb player_score.TieBreak) []player_score.PlayerScore {
+	base := time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+	players := []player_score.PlayerScore{
+		{PlayerID: "a", Score: 50, AchievedAt: base.Add(1 * time.Second), CompletionTime: 300},
+		{PlayerID: "b", Score: 40, AchievedAt: base.Add(2 * time.Second), CompletionTime: 200},
+		{PlayerID: "c", Score: 40, AchievedAt: base.Add(3 * time.Second), CompletionTime: 100},
+		{PlayerID: "d", Score: 30, AchievedAt: base.Add(4 * time.Second), CompletionTime: 600},
+		{PlayerID: "e", Score: 30, AchievedAt: base.Add(5 * time.Second), CompletionTime: 500},
+		{PlayerID: "f", Score: 30, AchievedAt: base.Add(6 * time.Second), CompletionTime: 400},
+		{PlayerID: "g", Score: 10, AchievedAt: base.Add(7 * time.Second), CompletionTime: 700},
+	}
+	sort.Slice(players, func(i, j int) bool { return tb.Before(players[i], players[j]) })
+	return players
+}
+
+// ranksOf returns the player IDs and ranks of a ranked page.
+func ranksOf(ranked []player_score.RankedPlayerScore) []string {
+	result := make([]string, len(ranked))
+	for i, player := range ranked {
+		result[i] = player.PlayerID + ":" + string(rune('0'+player.Rank))
+	}
+	return result
+}
+
+func TestRankSourcesAgree(t *testing.T) {
+	for _, tb := range []player_score.TieBreak{player_score.TieBreakEarliest, player_score.TieBreakFastest, player_score.TieBreakDense, player_score.TieBreakStandard} {
+		t.Run(string(tb), func(t *testing.T) {
+			players := rankingPlayers(tb)
+			pss := &PlayerScoreService{DBClient: repositories.NewMemoryDBClient(), CacheClient: repositories.NewMemoryCacheClient()}
+			for _, player := range players {
+				if _, err := pss.DBClient.UpdateOrInsertPlayerScore("quiz", player_score.UpdateKeepLatest, player, nil); err != nil {
+					t.Fatalf("UpdateOrInsertPlayerScore error = %v", err)
+				}
+			}
+			key := cachekey.Leaderboard("quiz")
+			if err := pss.CacheClient.AddToSet(key, tb, players); err != nil {
+				t.Fatalf("AddToSet error = %v", err)
+			}
+
+			want, _ := listRanks(players).rankPage(tb, players[2:5], 3)
+			for name, source := range map[string]rankSource{"cache": pss.cacheRanks(key, "quiz"), "db": pss.dbRanks("quiz", tb)} {
+				got, err := source.rankPage(tb, players[2:5], 0)
+				if err != nil {
+					t.Fatalf("%s: rankPage error = %v", name, err)
+				}
+				if !reflect.DeepEqual(ranksOf(got), ranksOf(want)) {
+					t.Errorf("%s: rankPage = %v, want %v", name, ranksOf(got), ranksOf(want))
+				}
+			}
+		})
+	}
+}
+
+func TestCacheRanksCountFromTheDatabase(t *testing.T) {
+	for _, tb := range []player_score.TieBreak{player_score.TieBreakDense, player_score.TieBreakStandard} {
+		t.Run(string(tb), func(t *testing.T) {
+			players := rankingPlayers(tb)
+			pss := &PlayerScoreService{DBClient: repositories.NewMemoryDBClient(), CacheClient: repositories.NewMemoryCacheClient()}
+			key := cachekey.Leaderboard("quiz")
+			if err := pss.CacheClient.AddToSet(key, tb, players); err != nil {
+				t.Fatalf("AddToSet error = %v", err)
+			}
+
+			// The database is ahead of the cache by one player above everyone else
+			for _, player := range append([]player_score.PlayerScore{{PlayerID: "z", Score: 99}}, players...) {
+				if _, err := pss.DBClient.UpdateOrInsertPlayerScore("quiz", player_score.UpdateKeepLatest, player, nil); err != nil {
+					t.Fatalf("UpdateOrInsertPlayerScore error = %v", err)
+				}
+			}
+
+			want, err := pss.dbRanks("quiz", tb).rankPage(tb, players[3:6], 0)
+			if err != nil {
+				t.Fatalf("db: rankPage error = %v", err)
+			}
+			got, err := pss.cacheRanks(key, "quiz").rankPage(tb, players[3:6], 0)
+			if err != nil {
+				t.Fatalf("cache: rankPage error = %v", err)
+			}
+			if !reflect.DeepEqual(ranksOf(got), ranksOf(want)) {
+				t.Errorf("cache ranks = %v, want the database ranks %v", ranksOf(got), ranksOf(want))
+			}
+		})
+	}
+}
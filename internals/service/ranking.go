@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"quiz/internals/domain/player_score"
+)
+
+// rankSource answers the queries needed to rank players under a tie break policy,
+// backed by either the cached leaderboard or the database.
+type rankSource struct {
+	position    func(playerID string) (int64, int, error)     // Position (starting at 1) and score of a player
+	countHigher func(score int, distinct bool) (int64, error) // Number of players (or distinct scores) above a score
+}
+
+// cacheRanks returns a rankSource reading positions from the cached leaderboard identified by the key.
+// Shared ranks count the scores above a player in the database, whether they count players or distinct scores,
+// since a ZSET can only count distinct scores by reading every higher member. Both policies thus take their
+// counts from the same source, even when the cache is behind the database.
+func (pss *PlayerScoreService) cacheRanks(key, boardID string) rankSource {
+	return rankSource{
+		position: func(playerID string) (int64, int, error) { return pss.CacheClient.GetRank(key, playerID) },
+		countHigher: func(score int, distinct bool) (int64, error) {
+			return pss.DBClient.CountHigherScores(boardID, score, distinct)
+		},
+	}
+}
+
+// dbRanks returns a rankSource reading the board from the database.
+func (pss *PlayerScoreService) dbRanks(boardID string, tb player_score.TieBreak) rankSource {
+	return rankSource{
+		position: func(playerID string) (int64, int, error) { return pss.DBClient.GetPlayerRank(boardID, tb, playerID) },
+		countHigher: func(score int, distinct bool) (int64, error) {
+			return pss.DBClient.CountHigherScores(boardID, score, distinct)
+		},
+	}
+}
+
+// listRanks returns a rankSource ranking players within a list sorted with TieBreak.Before, such as the friends of a player.
+func listRanks(players []player_score.PlayerScore) rankSource {
+	return rankSource{
+		position: func(playerID string) (int64, int, error) {
+			for i, player := range players {
+				if player.PlayerID == playerID {
+					return int64(i + 1), player.Score, nil
+				}
+			}
+			return 0, 0, fmt.Errorf("player %q is not in the list", playerID)
+		},
+		countHigher: func(score int, distinct bool) (int64, error) {
+			higher := make(map[int]bool)
+			count := int64(0)
+			for _, player := range players {
+				if player.Score > score && (!distinct || !higher[player.Score]) {
+					higher[player.Score] = true
+					count++
+				}
+			}
+			return count, nil
+		},
+	}
+}
+
+// rankOf converts the position and score of a player into their rank under the policy.
+// Ordered policies rank players by position, shared policies count the scores above the player.
+func (rs rankSource) rankOf(tb player_score.TieBreak, position int64, score int) (int64, error) {
+	if !tb.Shared() {
+		return position, nil
+	}
+
+	higher, err := rs.countHigher(score, tb == player_score.TieBreakDense)
+	if err != nil {
+		return 0, err
+	}
+	return higher + 1, nil
+}
+
+// rankPage attaches absolute ranks to a page of the leaderboard under the policy.
+// firstPosition is the position of the first entry when it is known from the offset, or zero for cursor pages.
+func (rs rankSource) rankPage(tb player_score.TieBreak, players []player_score.PlayerScore, firstPosition int64) ([]player_score.RankedPlayerScore, error) {
+	ranked := make([]player_score.RankedPlayerScore, len(players))
+	if len(players) == 0 {
+		return ranked, nil
+	}
+
+	switch tb {
+	case player_score.TieBreakDense:
+		// The first entry is ranked after every distinct higher score, each new score adds one
+		higher, err := rs.countHigher(players[0].Score, true)
+		if err != nil {
+			return nil, err
+		}
+		rank := higher + 1
+		for i, player := range players {
+			if i > 0 && player.Score != players[i-1].Score {
+				rank++
+			}
+			ranked[i] = player_score.RankedPlayerScore{PlayerScore: player, Rank: rank}
+		}
+
+	case player_score.TieBreakStandard:
+		// The first entry is ranked after every higher score, and every later score is ranked after
+		// all players scoring at least as much as the first entry plus the page entries in between
+		higher, err := rs.countHigher(players[0].Score, false)
+		if err != nil {
+			return nil, err
+		}
+		atLeastFirst, err := rs.countHigher(players[0].Score-1, false)
+		if err != nil {
+			return nil, err
+		}
+		rank, below := higher+1, int64(0)
+		for i, player := range players {
+			if player.Score != players[0].Score {
+				if player.Score != players[i-1].Score {
+					rank = atLeastFirst + below + 1
+				}
+				below++
+			}
+			ranked[i] = player_score.RankedPlayerScore{PlayerScore: player, Rank: rank}
+		}
+
+	default:
+		// Ordered policies rank every player by position
+		if firstPosition == 0 {
+			position, _, err := rs.position(players[0].PlayerID)
+			if err != nil {
+				return nil, err
+			}
+			firstPosition = position
+		}
+		for i, player := range players {
+			ranked[i] = player_score.RankedPlayerScore{PlayerScore: player, Rank: firstPosition + int64(i)}
+		}
+	}
+
+	return ranked, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"quiz/internals/domain/player_score"
+	"reflect"
+	"testing"
+)
+
+func TestRankPage(t *testing.T) {
+	tests := []struct {
+		name string
+		tb   player_score.TieBreak
+		want []string // Ranks of the whole leaderboard
+	}{
+		{"earliest", player_score.TieBreakEarliest, []string{"a:1", "b:2", "c:3", "d:4", "e:5", "f:6", "g:7"}},
+		{"fastest", player_score.TieBreakFastest, []string{"a:1", "c:2", "b:3", "f:4", "e:5", "d:6", "g:7"}},
+		{"dense", player_score.TieBreakDense, []string{"a:1", "c:2", "b:2", "f:3", "e:3", "d:3", "g:4"}},
+		{"standard", player_score.TieBreakStandard, []string{"a:1", "c:2", "b:2", "f:4", "e:4", "d:4", "g:7"}},
+	}
+
+	for _, tt := range tests {
+		players := rankingPlayers(tt.tb)
+		for start := 0; start < len(players); start++ {
+			for end := start + 1; end <= len(players); end++ {
+				for _, cursor := range []bool{false, true} {
+					// Cursor pages do not know the position of their first entry
+					firstPosition := int64(start + 1)
+					if cursor {
+						firstPosition = 0
+					}
+
+					ranked, err := listRanks(players).rankPage(tt.tb, players[start:end], firstPosition)
+					if err != nil {
+						t.Fatalf("%s: rankPage(%d:%d) error = %v", tt.name, start, end, err)
+					}
+					if got := ranksOf(ranked); !reflect.DeepEqual(got, tt.want[start:end]) {
+						t.Errorf("%s: rankPage(%d:%d, cursor %v) = %v, want %v", tt.name, start, end, cursor, got, tt.want[start:end])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRankPageEmpty(t *testing.T) {
+	for _, tb := range []player_score.TieBreak{player_score.TieBreakEarliest, player_score.TieBreakDense, player_score.TieBreakStandard} {
+		ranked, err := listRanks(nil).rankPage(tb, nil, 0)
+		if err != nil || len(ranked) != 0 {
+			t.Errorf("%s: rankPage(nil) = %v, %v, want an empty page", tb, ranked, err)
+		}
+	}
+}
+
+func TestRankOf(t *testing.T) {
+	tests := []struct {
+		tb       player_score.TieBreak
+		playerID string
+		want     int64
+	}{
+		{player_score.TieBreakEarliest, "c", 3},
+		{player_score.TieBreakFastest, "c", 2},
+		{player_score.TieBreakDense, "d", 3},
+		{player_score.TieBreakDense, "g", 4},
+		{player_score.TieBreakStandard, "d", 4},
+		{player_score.TieBreakStandard, "g", 7},
+	}
+
+	for _, tt := range tests {
+		source := listRanks(rankingPlayers(tt.tb))
+		position, score, err := source.position(tt.playerID)
+		if err != nil {
+			t.Fatalf("%s: position(%q) error = %v", tt.tb, tt.playerID, err)
+		}
+		if got, err := source.rankOf(tt.tb, position, score); err != nil || got != tt.want {
+			t.Errorf("%s: rankOf(%q) = %d, %v, want %d", tt.tb, tt.playerID, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrRebuildInProgress is returned when a leaderboard is rebuilt while another rebuild of it holds its lock.
+var ErrRebuildInProgress = errors.New("the leaderboard is being rebuilt by another instance")
+
+const (
+	rebuildLockTTL       = 30 * time.Second      // Longest time a crashed instance keeps other instances from rebuilding a leaderboard, the lock is renewed while the rebuild runs
+	rebuildPollInterval  = 50 * time.Millisecond // Interval at which an instance checks whether another instance finished its rebuild
+	rebuildCatchUpMargin = time.Minute           // How long before a rebuild started scores are copied again once it finished
+	emptyLeaderboardTTL  = 30 * time.Second      // How long reads of a leaderboard a rebuild found empty go to the database instead of rebuilding it again
+	generationTTL        = 24 * time.Hour        // How long the generation of a board outlives its last reset, far longer than any rebuild
+)
+
+// warmLeaderboard makes sure the cached leaderboard of the board is filled from the database, and reports whether it
+// holds any players once the rebuild finished. However many callers find the leaderboard cold, it is rebuilt only once:
+// the callers of one instance share a single rebuild, and the instances coordinate through a lock in the cache,
+// where an instance that does not get the lock waits for the holder to finish. A caller gives up and reports false
+// when ctx is done or after the service's RebuildWait, while the rebuild itself keeps running. A leaderboard a rebuild
+// recently found empty is not rebuilt again until its marker expires after emptyLeaderboardTTL or a score is created on it.
+func (pss *PlayerScoreService) warmLeaderboard(ctx context.Context, b board.Board) bool {
+	if empty, err := pss.CacheClient.HasMarker(cachekey.EmptyLeaderboard(b.ID)); err == nil && empty {
+		return false
+	}
+
+	result := pss.rebuilds.DoChan(b.ID, func() (interface{}, error) {
+		return pss.rebuildLeaderboard(b), nil
+	})
+
+	select {
+	case res := <-result:
+		return res.Val.(bool)
+	case <-ctx.Done():
+		pss.Logger.Info("Request ended while waiting for the leaderboard rebuild", zap.String("board_id", b.ID), zap.Error(ctx.Err()))
+		return false
+	case <-time.After(pss.RebuildWait):
+		pss.Logger.Info("Gave up waiting for the leaderboard rebuild", zap.String("board_id", b.ID))
+		return false
+	}
+}
+
+// rebuildLeaderboard rebuilds the cached leaderboard of the board while holding its rebuild lock, or waits up to
+// RebuildWait for the instance holding the lock to release it, the longest a reader waits anyway. It is shared by
+// every caller waiting for the board, so it is bounded by the service's context rather than one caller's request.
+// It reports whether the leaderboard holds any players afterwards.
+func (pss *PlayerScoreService) rebuildLeaderboard(b board.Board) bool {
+	key, lockKey := cachekey.Leaderboard(b.ID), cachekey.RebuildLock(b.ID)
+	token := lockToken()
+
+	acquired, err := pss.CacheClient.AcquireLock(lockKey, token, rebuildLockTTL)
+	if err != nil {
+		pss.Logger.Error("Error acquiring the leaderboard rebuild lock", zap.String("board_id", b.ID), zap.Error(err))
+		return false
+	}
+
+	if acquired {
+		locked, release := pss.holdRebuildLock(b.ID, token)
+		defer release()
+
+		// Another instance may have finished a rebuild between the caller's check and the lock
+		if size, err := pss.CacheClient.GetSetSize(key); err != nil || size == 0 {
+			pss.buildLeaderboard(locked, b)
+		}
+	} else {
+		pss.Logger.Info("Leaderboard rebuilt by another instance, waiting", zap.String("board_id", b.ID))
+		ticker := time.NewTicker(rebuildPollInterval)
+		defer ticker.Stop()
+		for deadline := time.Now().Add(pss.RebuildWait); time.Now().Before(deadline); {
+			select {
+			case <-pss.CTX.Done():
+				return false
+			case <-ticker.C:
+			}
+			if held, err := pss.CacheClient.HasMarker(lockKey); err != nil || !held {
+				break
+			}
+		}
+	}
+
+	size, err := pss.CacheClient.GetSetSize(key)
+	return err == nil && size > 0
+}
+
+// RebuildBoard rebuilds the cached all-time leaderboard of the board and those of the current periods of its windows
+// from the database, whether they are cold or not, and returns the number of players cached. Leaderboards of past
+// periods and of countries and regions are left to be rebuilt on their next read. It returns ErrRebuildInProgress
+// when another rebuild of one of the leaderboards holds its lock, after rebuilding the others.
+func (pss *PlayerScoreService) RebuildBoard(b board.Board) (int, error) {
+	pss.Logger.Info("RebuildBoard method called", zap.String("board_id", b.ID))
+
+	scopes := append([]string{b.ID}, pss.Windows.ScopeIDs(b.ID, time.Now())...)
+	total, inProgress := 0, false
+	for _, scopeID := range scopes {
+		scope := b
+		scope.ID = scopeID
+
+		token := lockToken()
+		acquired, err := pss.CacheClient.AcquireLock(cachekey.RebuildLock(scopeID), token, rebuildLockTTL)
+		if err != nil {
+			pss.Logger.Error("Error acquiring the leaderboard rebuild lock", zap.String("board_id", scopeID), zap.Error(err))
+			return total, err
+		}
+		if !acquired {
+			pss.Logger.Info("Leaderboard rebuilt by another instance, skipping it", zap.String("board_id", scopeID))
+			inProgress = true
+			continue
+		}
+
+		locked, release := pss.holdRebuildLock(scopeID, token)
+		count, err := pss.buildLeaderboard(locked, scope)
+		release()
+		if err != nil {
+			return total, err
+		}
+		total += count
+	}
+
+	if inProgress {
+		return total, ErrRebuildInProgress
+	}
+	return total, nil
+}
+
+// buildLeaderboard copies every player of the board from the database into a new leaderboard, in batches of
+// cacheWarmBatchSize, and returns the number of players copied. The leaderboard is built under a temporary key and
+// swapped in with one RENAME, so readers never see it half built. Scores changed while it was built may have been
+// applied to the leaderboard it replaced, so the players whose score changed, or who have an outbox entry for the
+// board, from rebuildCatchUpMargin before the rebuild started are copied again afterwards. The copy removes the players
+// whose score is gone, so members removed from the board while it was built do not come back with the swap. A reset
+// removes every score at once, so the board's generation is read before the first batch, and the leaderboard is
+// discarded when a reset bumped it in the meantime. The leaderboard of a window period expires with the scores of its
+// period instead of LeaderboardTTL after its last access. A leaderboard without any players is marked empty for
+// emptyLeaderboardTTL. The caller holds the rebuild lock of the board while ctx is live, the rebuild is abandoned
+// when the lock is lost.
+func (pss *PlayerScoreService) buildLeaderboard(ctx context.Context, b board.Board) (int, error) {
+	tb := b.TieBreak.OrDefault()
+	key, tempKey := cachekey.Leaderboard(b.ID), cachekey.RebuildTemp(b.ID, lockToken())
+	started := time.Now().UTC()
+
+	generation, err := pss.CacheClient.GetGeneration(cachekey.Generation(b.ID))
+	if err != nil {
+		pss.Logger.Error("Error reading the board generation before rebuilding the cache", zap.String("board_id", b.ID), zap.Error(err))
+		return 0, err
+	}
+
+	count := 0
+	page := player_score.PageRequest{Limit: cacheWarmBatchSize}
+	for {
+		players, err := pss.DBClient.GetTopPlayers(b.ID, tb, page)
+		if err == nil {
+			err = pss.CacheClient.AddToSet(tempKey, tb, players)
+		}
+		if err == nil {
+			err = ctx.Err() // The rebuild lock was lost, another instance may be rebuilding the leaderboard by now
+		}
+		if err != nil {
+			pss.Logger.Error("Error copying records from DB while rebuilding the cache", zap.String("board_id", b.ID), zap.Error(err))
+			if err := pss.CacheClient.DeleteKey(tempKey); err != nil {
+				pss.Logger.Error("Error deleting the partially rebuilt leaderboard", zap.String("board_id", b.ID), zap.Error(err))
+			}
+			return count, err
+		}
+		count += len(players)
+
+		if int64(len(players)) < page.Limit {
+			break
+		}
+		cursor := player_score.CursorOf(players[len(players)-1])
+		page.After = &cursor
+	}
+
+	replaced, err := pss.CacheClient.ReplaceSet(tempKey, key, cachekey.Generation(b.ID), generation)
+	if err != nil {
+		pss.Logger.Error("Error swapping in the rebuilt leaderboard", zap.String("board_id", b.ID), zap.Error(err))
+		return count, err
+	}
+	if !replaced {
+		pss.Logger.Info("Scores were reset during the rebuild, discarding the rebuilt leaderboard", zap.String("board_id", b.ID))
+		return 0, nil
+	}
+	if scope, ok := pss.Windows.Scope(b.ID); ok {
+		if err := pss.CacheClient.ExpireAt(key, scope.ExpiresAt); err != nil {
+			pss.Logger.Error("Error setting the expiry of the window leaderboard", zap.String("board_id", b.ID), zap.Error(err))
+			return count, err
+		}
+	}
+
+	since := started.Add(-rebuildCatchUpMargin)
+	var playerIDs []string
+	changed, err := pss.DBClient.GetPlayersChangedSince(b.ID, since)
+	if err == nil {
+		var touched []string
+		if touched, err = pss.Outbox.OutboxClient.GetPlayersWithEntriesSince(b.ID, since); err == nil {
+			playerIDs = catchUpPlayers(changed, touched)
+		}
+	}
+	if err != nil {
+		pss.Logger.Error("Error retrieving records changed during the rebuild", zap.String("board_id", b.ID), zap.Error(err))
+		return count, err
+	}
+	if err := copyPlayerScores(pss.DBClient, pss.CacheClient, b.ID, tb, playerIDs); err != nil {
+		pss.Logger.Error("Error copying records changed during the rebuild", zap.String("board_id", b.ID), zap.Error(err))
+		if errors.Is(err, errCopyOvertaken) {
+			// The scores keep changing, a leaderboard that may hold stale ones is rebuilt by the next read instead
+			if err := pss.CacheClient.DeleteKey(key); err != nil {
+				pss.Logger.Error("Error dropping the rebuilt leaderboard", zap.String("board_id", b.ID), zap.Error(err))
+			}
+		}
+		return count, err
+	}
+
+	if count == 0 && len(playerIDs) == 0 {
+		if err := pss.CacheClient.SetMarker(cachekey.EmptyLeaderboard(b.ID), emptyLeaderboardTTL); err != nil {
+			pss.Logger.Error("Error marking the leaderboard as empty", zap.String("board_id", b.ID), zap.Error(err))
+		}
+	}
+
+	pss.Logger.Info("Leaderboard cached successfully", zap.String("board_id", b.ID), zap.Int("count", count), zap.Int("caught_up", len(playerIDs)))
+	return count, nil
+}
+
+// holdRebuildLock keeps the rebuild lock of the scope taken with the token held while the leaderboard is rebuilt, see
+// holdLock. The returned function stops renewing the lock and releases it; release failures are only logged, the lock
+// expires after rebuildLockTTL anyway.
+func (pss *PlayerScoreService) holdRebuildLock(scopeID, token string) (context.Context, func()) {
+	lockKey := cachekey.RebuildLock(scopeID)
+	held, stop := holdLock(pss.CTX, pss.CacheClient, pss.Logger, lockKey, token, rebuildLockTTL)
+	return held, func() {
+		stop()
+		if err := pss.CacheClient.ReleaseLock(lockKey, token); err != nil {
+			pss.Logger.Error("Error releasing the leaderboard rebuild lock", zap.String("board_id", scopeID), zap.Error(err))
+		}
+	}
+}
+
+// holdLock renews the lock identified by the key, taken with the token, every third of its TTL until the returned
+// function is called, so work that outlasts the TTL keeps it. The returned context is cancelled when the lock could
+// not be renewed because it expired or was taken over, the holder must then stop its work. Renewal errors are logged
+// and retried on the next tick, the lock still has time left until then.
+func holdLock(ctx context.Context, cache repositories.ICacheRepository, logger *zap.Logger, key, token string, ttl time.Duration) (context.Context, func()) {
+	held, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-held.Done():
+				return
+			case <-ticker.C:
+			}
+
+			renewed, err := cache.RenewLock(key, token, ttl)
+			if err != nil {
+				logger.Error("Error renewing lock", zap.String("key", key), zap.Error(err))
+				continue
+			}
+			if !renewed {
+				logger.Info("Lock lost before the work holding it finished", zap.String("key", key))
+				cancel()
+				return
+			}
+		}
+	}()
+	return held, cancel
+}
+
+// invalidateRebuilds bumps the generation of the board, so rebuilds of its leaderboards that started before its scores
+// were reset or deleted discard their result instead of swapping the removed scores back in. It is called after the
+// scores were removed from the database and before the cached leaderboards are dropped.
+func invalidateRebuilds(cache repositories.ICacheRepository, boardID string) error {
+	return cache.BumpGeneration(cachekey.Generation(boardID), generationTTL)
+}
+
+// lockToken returns a random token identifying the holder of a lock or the owner of a temporary key.
+func lockToken() string {
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + strconv.FormatUint(uint64(rand.Uint32()), 36)
+}
+
+// catchUpPlayers returns the IDs of the changed players and of the players with outbox entries, each once.
+func catchUpPlayers(changed []player_score.PlayerScore, touched []string) []string {
+	seen := make(map[string]bool, len(changed)+len(touched))
+	playerIDs := make([]string, 0, len(changed)+len(touched))
+	for _, player := range changed {
+		touched = append(touched, player.PlayerID)
+	}
+	for _, playerID := range touched {
+		if !seen[playerID] {
+			seen[playerID] = true
+			playerIDs = append(playerIDs, playerID)
+		}
+	}
+	return playerIDs
+}
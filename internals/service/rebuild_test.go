@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/profile"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"testing"
+	"time"
+)
+
+func TestWarmLeaderboardStopsWaitingWhenTheRequestEnds(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	ts.Scores.RebuildWait = time.Minute
+
+	// Another instance holds the rebuild lock and never finishes
+	if acquired, err := ts.Cache.AcquireLock(cachekey.RebuildLock(b.ID), "other", time.Minute); err != nil || !acquired {
+		t.Fatalf("AcquireLock = %v, %v", acquired, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	if ts.Scores.warmLeaderboard(ctx, b) {
+		t.Errorf("warmLeaderboard reported a warm leaderboard while another instance rebuilds it")
+	}
+	if waited := time.Since(started); waited > time.Second {
+		t.Errorf("warmLeaderboard waited %v after the request ended", waited)
+	}
+}
+
+func TestWarmLeaderboardStopsWaitingAfterRebuildWait(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	ts.Scores.RebuildWait = 50 * time.Millisecond
+
+	if acquired, err := ts.Cache.AcquireLock(cachekey.RebuildLock(b.ID), "other", time.Minute); err != nil || !acquired {
+		t.Fatalf("AcquireLock = %v, %v", acquired, err)
+	}
+
+	started := time.Now()
+	if ts.Scores.warmLeaderboard(context.Background(), b) {
+		t.Errorf("warmLeaderboard reported a warm leaderboard while another instance rebuilds it")
+	}
+	if waited := time.Since(started); waited > time.Second {
+		t.Errorf("warmLeaderboard waited %v, want about the rebuild wait", waited)
+	}
+}
+
+// pagingDB is a database whose reads of leaderboard pages are followed by a concurrent write, the way scores change
+// while a rebuild copies the leaderboard.
+type pagingDB struct {
+	*repositories.MemoryDBClient
+	after func() // Called after the first page read
+}
+
+// GetTopPlayers reads the page and then runs the concurrent write once.
+func (db *pagingDB) GetTopPlayers(boardID string, tb player_score.TieBreak, page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+	players, err := db.MemoryDBClient.GetTopPlayers(boardID, tb, page)
+	if after := db.after; after != nil {
+		db.after = nil
+		after()
+	}
+	return players, err
+}
+
+func TestBuildLeaderboardCatchUpRemovesPlayersWhoLeft(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	for _, playerID := range []string{"p1", "p2"} {
+		if err := ts.DB.CreateProfile(profile.Profile{PlayerID: playerID, Country: "FR"}); err != nil {
+			t.Fatalf("CreateProfile error = %v", err)
+		}
+		if _, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: playerID, Score: 10}, testOrigin); err != nil {
+			t.Fatalf("AddOrUpdatePlayerScore error = %v", err)
+		}
+	}
+	france, err := ts.Scores.AttributeBoard(b, profile.AttributeCountry, "FR")
+	if err != nil {
+		t.Fatalf("AttributeBoard error = %v", err)
+	}
+
+	// p1 moves to another country once the rebuild copied them, before the relay applies the move
+	country := "DE"
+	ts.Scores.DBClient = &pagingDB{MemoryDBClient: ts.DB, after: func() {
+		if _, err := ts.DB.UpdateProfile("p1", profile.Update{Country: &country}, time.Now().UTC()); err != nil {
+			t.Fatalf("UpdateProfile error = %v", err)
+		}
+	}}
+	if _, err := ts.Scores.buildLeaderboard(context.Background(), france); err != nil {
+		t.Fatalf("buildLeaderboard error = %v", err)
+	}
+
+	if _, cached := ts.cachedScore(t, france.ID, "p1"); cached {
+		t.Errorf("player who left the country during the rebuild is still cached")
+	}
+	if _, cached := ts.cachedScore(t, france.ID, "p2"); !cached {
+		t.Errorf("player who stayed is not cached")
+	}
+}
+
+func TestBuildLeaderboardDiscardsARebuildOverlappingAReset(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	if _, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: "p1", Score: 10}, testOrigin); err != nil {
+		t.Fatalf("AddOrUpdatePlayerScore error = %v", err)
+	}
+
+	// The scores are reset once the rebuild copied them, before it swaps its leaderboard in
+	ts.Scores.DBClient = &pagingDB{MemoryDBClient: ts.DB, after: func() {
+		if err := ts.Scores.ResetScores(b); err != nil {
+			t.Fatalf("ResetScores error = %v", err)
+		}
+	}}
+	count, err := ts.Scores.buildLeaderboard(context.Background(), b)
+	if err != nil || count != 0 {
+		t.Fatalf("buildLeaderboard = %d, %v, want the rebuild discarded", count, err)
+	}
+
+	if _, cached := ts.cachedScore(t, b.ID, "p1"); cached {
+		t.Errorf("score removed by the reset was swapped back in")
+	}
+	if size, _ := ts.Cache.GetSetSize(cachekey.Leaderboard(b.ID)); size != 0 {
+		t.Errorf("cached leaderboard size = %d, want it left cold", size)
+	}
+}
+
+// expiringCache is a cache recording the expiries set on its keys, which the in-memory cache ignores.
+type expiringCache struct {
+	*repositories.MemoryCacheClient
+	expiries map[string]time.Time // Expiry set on each key
+}
+
+// ExpireAt records the expiry of the key.
+func (c *expiringCache) ExpireAt(key string, at time.Time) error {
+	c.expiries[key] = at
+	return c.MemoryCacheClient.ExpireAt(key, at)
+}
+
+func TestBuildLeaderboardExpiresWindowLeaderboardsWithTheirPeriod(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	ts.Scores.Windows = window.Schedule{Windows: []window.Window{window.Daily}, Location: time.UTC, Retention: time.Hour}
+	if _, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: "p1", Score: 10}, testOrigin); err != nil {
+		t.Fatalf("AddOrUpdatePlayerScore error = %v", err)
+	}
+
+	cache := &expiringCache{MemoryCacheClient: ts.Cache, expiries: map[string]time.Time{}}
+	ts.Scores.CacheClient = cache
+	ts.warm(t, b)
+
+	daily := ts.Scores.Windows.Scopes(b.ID, time.Now())[0]
+	if got, ok := cache.expiries[cachekey.Leaderboard(daily.ID)]; !ok || !got.Equal(daily.ExpiresAt) {
+		t.Errorf("expiry of the daily leaderboard = %v, want %v", got, daily.ExpiresAt)
+	}
+	if got, ok := cache.expiries[cachekey.Leaderboard(b.ID)]; ok {
+		t.Errorf("expiry of the all-time leaderboard = %v, want it to slide", got)
+	}
+}
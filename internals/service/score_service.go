@@ -2,108 +2,637 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"quiz/internals/domain/board"
 	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/profile"
+	"quiz/internals/domain/score_event"
+	"quiz/internals/domain/window"
 	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"sort"
+	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
+// ErrCompletionTimeRequired is returned when a score is submitted without a completion time to a board using the "fastest" tie break policy.
+var ErrCompletionTimeRequired = errors.New("completion_ms is required on boards using the fastest tie break policy")
+
+// ErrInvalidIncrement is returned when an increment has no player, a zero delta or a floor above its ceiling.
+var ErrInvalidIncrement = errors.New("an increment needs a player_id, a non-zero delta and a floor not above its ceiling")
+
+// ErrUnknownWindow is returned when a leaderboard window is requested that is not kept by the service's schedule.
+var ErrUnknownWindow = errors.New("the requested window is not kept for this leaderboard")
+
+// ErrPlayerNotFound is returned when a player has no score on the board.
+var ErrPlayerNotFound = errors.New("player not found")
+
+// cacheWarmBatchSize is the number of players loaded from the database per batch when warming the cache.
+const cacheWarmBatchSize = 1000
+
+// missingPlayerTTL is how long a player without a score is remembered as missing by the cache.
+const missingPlayerTTL = 30 * time.Second
+
 type PlayerScoreService struct {
-	DBClient    repositories.IDBRepository    // Interface for database operations
-	CacheClient repositories.ICacheRepository // Interface for cache operations
-	CTX         context.Context               // Context for managing request-scoped values
-	Logger      *zap.Logger                   // Logger for structured logging
+	DBClient      repositories.IDBRepository      // Interface for database operations
+	CacheClient   repositories.ICacheRepository   // Interface for cache operations
+	HistoryClient repositories.IHistoryRepository // Interface for the score history
+	Windows       window.Schedule                 // Windowed leaderboards kept next to the all-time leaderboard of every board
+	Groups        *GroupService                   // Service keeping the group leaderboards in sync with player scores
+	ProfileClient repositories.IProfileRepository // Interface for reading the player profiles joined into leaderboards
+	Outbox        *OutboxRelay                    // Relay applying the score changes recorded in the outbox to the cache
+	RebuildWait   time.Duration                   // Longest time a read waits for the rebuild of a cold cached leaderboard before it is served from the database
+	rebuilds      singleflight.Group              // Rebuilds of cold cached leaderboards in progress, by board ID
+	CTX           context.Context                 // Context for managing request-scoped values
+	Logger        *zap.Logger                     // Logger for structured logging
 }
 
-// NewPlayerScoreService initializes a new PlayerScoreService with the provided database, cache clients, context, and logger.
-func NewPlayerScoreService(db_client repositories.IDBRepository, cache_client repositories.ICacheRepository, ctx context.Context, custom_logger *zap.Logger) *PlayerScoreService {
+// NewPlayerScoreService initializes a new PlayerScoreService with the provided database, cache and history clients, window schedule, group service, profile repository, outbox relay, rebuild wait, context, and logger.
+func NewPlayerScoreService(db_client repositories.IDBRepository, cache_client repositories.ICacheRepository, history_client repositories.IHistoryRepository, windows window.Schedule, groups *GroupService, profile_client repositories.IProfileRepository, outbox_relay *OutboxRelay, rebuild_wait time.Duration, ctx context.Context, custom_logger *zap.Logger) *PlayerScoreService {
 	return &PlayerScoreService{
-		DBClient:    db_client,
-		CacheClient: cache_client,
-		CTX:         ctx,
-		Logger:      custom_logger,
+		DBClient:      db_client,
+		CacheClient:   cache_client,
+		HistoryClient: history_client,
+		Windows:       windows,
+		Groups:        groups,
+		ProfileClient: profile_client,
+		Outbox:        outbox_relay,
+		RebuildWait:   rebuild_wait,
+		CTX:           ctx,
+		Logger:        custom_logger,
 	}
 }
 
-// AddOrUpdatePlayerScore adds or updates the player's score in the database and cache.
-func (pss *PlayerScoreService) AddOrUpdatePlayerScore(playerScore player_score.PlayerScore) error {
-	pss.Logger.Info("AddOrUpdatePlayerScore method called", zap.String("player_id", playerScore.PlayerID))
+// AddOrUpdatePlayerScore adds or updates the player's score on the board in the database and cache as allowed by the
+// board's update policy, and records the change in the score history with the given origin.
+// The returned change reports whether the submitted score replaced the stored one.
+// The achievement time used by the "earliest" tie break policy is set here, and boards using the
+// "fastest" policy require a completion time.
+// The leaderboards of the current period of every scheduled window are updated the same way in the same transaction,
+// each under its own policy check, and the stored score is copied to the leaderboards of the player's country and region
+// in that transaction too.
+func (pss *PlayerScoreService) AddOrUpdatePlayerScore(b board.Board, playerScore player_score.PlayerScore, origin score_event.Origin) (player_score.ScoreChange, error) {
+	pss.Logger.Info("AddOrUpdatePlayerScore method called", zap.String("board_id", b.ID), zap.String("player_id", playerScore.PlayerID))
+
+	tb, up := b.TieBreak.OrDefault(), b.UpdatePolicy.OrDefault()
+	if tb == player_score.TieBreakFastest && playerScore.CompletionTime <= 0 {
+		return player_score.ScoreChange{}, ErrCompletionTimeRequired
+	}
+	playerScore.AchievedAt = time.Now().UTC().Truncate(time.Second) // Tie values are kept with a precision of one second
+
+	// A score kept out of the all-time board may still be the best of the current day, week or month
+	change, err := pss.storeScore(b.ID, up, playerScore, pss.Windows.Scopes(b.ID, playerScore.AchievedAt))
+	if err != nil {
+		return player_score.ScoreChange{}, err
+	}
+
+	if change.Created {
+		pss.forgetMissing(b.ID, playerScore.PlayerID)
+	}
+
+	if change.Replaced {
+		pss.cacheScore(b, playerScore.PlayerID)
+		pss.recordChange(b.ID, playerScore.PlayerID, change, origin)
+		go pss.Groups.RefreshPlayerGroups(b.ID, playerScore.PlayerID)
+	} else {
+		pss.Logger.Info("Submitted score kept out by the update policy", zap.String("player_id", playerScore.PlayerID), zap.String("update_policy", string(up)))
+	}
+
+	pss.Logger.Info(fmt.Sprintf("Create or update operations were successful for player: %v", playerScore))
+	return change, nil
+}
 
+// storeScore writes the player's score to the database on the board and the given window periods as allowed by the
+// update policy, and the database copies it to the board's attribute leaderboards in the same transaction. Replaced
+// scores are recorded in the outbox with the writes, and the outbox relay applies them to the cached leaderboards.
+func (pss *PlayerScoreService) storeScore(boardID string, up player_score.UpdatePolicy, playerScore player_score.PlayerScore, windows []window.Scope) (player_score.ScoreChange, error) {
 	// Update or insert player score in the database
-	if err := pss.DBClient.UpdateOrInsertPlayerScore(playerScore); err != nil {
-		pss.Logger.Error("Error updating or inserting player score in DB", zap.String("player_id", playerScore.PlayerID), zap.Error(err))
-		return err
+	change, err := pss.DBClient.UpdateOrInsertPlayerScore(boardID, up, playerScore, windows)
+	if err != nil {
+		pss.Logger.Error("Error updating or inserting player score in DB", zap.String("board_id", boardID), zap.String("player_id", playerScore.PlayerID), zap.Error(err))
+		return player_score.ScoreChange{}, err
+	}
+
+	pss.Logger.Info("Player score updated/inserted in DB", zap.String("board_id", boardID), zap.String("player_id", playerScore.PlayerID))
+
+	if change.Replaced || len(windows) > 0 { // A score kept out of the board may still have replaced one of a window
+		pss.Outbox.Notify()
+	}
+	return change, nil
+}
+
+// IncrementPlayerScore atomically changes the player's score on the board by the increment's delta, keeping the
+// result between the optional floor and ceiling, and records the change in the score history with the given origin.
+// A negative delta decrements the score, and players without a score start from zero. Increments are applied
+// whatever the board's update policy is.
+// The scores of the current period of every scheduled window are incremented too in the same transaction, so they hold
+// the points earned in that period, and the resulting score is copied to the leaderboards of the player's country and region
+// in that transaction too. The floor and ceiling bound the score of each window period on its own. A score already at
+// the bound the increment moves it towards is left as it was, without a history event or an outbox entry.
+func (pss *PlayerScoreService) IncrementPlayerScore(b board.Board, inc player_score.Increment, origin score_event.Origin) (player_score.ScoreChange, error) {
+	pss.Logger.Info("IncrementPlayerScore method called", zap.String("board_id", b.ID), zap.String("player_id", inc.PlayerID), zap.Int("delta", inc.Delta))
+
+	if inc.PlayerID == "" || inc.Delta == 0 || (inc.Floor != nil && inc.Ceiling != nil && *inc.Floor > *inc.Ceiling) {
+		return player_score.ScoreChange{}, ErrInvalidIncrement
+	}
+
+	inc.AchievedAt = time.Now().UTC().Truncate(time.Second) // Tie values are kept with a precision of one second
+
+	_, change, err := pss.incrementScore(b.ID, inc, pss.Windows.Scopes(b.ID, inc.AchievedAt))
+	if err != nil {
+		return player_score.ScoreChange{}, err
+	}
+	if !change.Replaced {
+		pss.Logger.Info("Increment kept at its bound, the board score is unchanged", zap.String("board_id", b.ID), zap.String("player_id", inc.PlayerID), zap.Int("score", change.NewScore))
+		return change, nil
+	}
+	pss.cacheScore(b, inc.PlayerID)
+
+	if change.Created {
+		pss.forgetMissing(b.ID, inc.PlayerID)
+	}
+
+	pss.recordChange(b.ID, inc.PlayerID, change, origin)
+	go pss.Groups.RefreshPlayerGroups(b.ID, inc.PlayerID)
+
+	return change, nil
+}
+
+// incrementScore increments the player's score in the database on the board and the given window periods.
+// The changes are recorded in the outbox with the writes, and the outbox relay applies them to the cached leaderboards.
+func (pss *PlayerScoreService) incrementScore(boardID string, inc player_score.Increment, windows []window.Scope) (player_score.PlayerScore, player_score.ScoreChange, error) {
+	// Increment the player score in the database
+	player, change, err := pss.DBClient.IncrementPlayerScore(boardID, inc, windows)
+	if err != nil {
+		pss.Logger.Error("Error incrementing player score in DB", zap.String("board_id", boardID), zap.String("player_id", inc.PlayerID), zap.Error(err))
+		return player_score.PlayerScore{}, player_score.ScoreChange{}, err
 	}
 
-	pss.Logger.Info("Player score updated/inserted in DB", zap.String("player_id", playerScore.PlayerID))
+	pss.Logger.Info("Player score incremented in DB", zap.String("board_id", boardID), zap.String("player_id", inc.PlayerID), zap.Int("new_score", change.NewScore))
+
+	pss.Outbox.Notify()
+	return player, change, nil
+}
+
+// cacheScore copies the player's current score on the board to its cached leaderboard right after a write committed,
+// so the player reads their own write at once instead of waiting for the outbox relay. The copy is the relay's own
+// (see copyPlayerScores), so it never leaves an older score behind the relay's copy. When the score kept changing
+// while it was copied, the cached leaderboard is dropped and rebuilt from the database on its next read, since the last
+// copy may have overwritten a newer one. Other failures are only logged, the relay copies the score afterwards anyway.
+func (pss *PlayerScoreService) cacheScore(b board.Board, playerID string) {
+	err := copyPlayerScore(pss.DBClient, pss.CacheClient, b.ID, b.TieBreak.OrDefault(), playerID)
+	if errors.Is(err, errCopyOvertaken) {
+		pss.Logger.Info("Score changed again while it was cached, dropping the cached leaderboard", zap.String("board_id", b.ID), zap.String("player_id", playerID))
+		err = pss.CacheClient.DeleteKey(cachekey.Leaderboard(b.ID))
+	}
+	if err != nil {
+		pss.Logger.Error("Error updating the cache for player, left to the outbox relay", zap.String("board_id", b.ID), zap.String("player_id", playerID), zap.Error(err))
+	}
+}
 
-	// Update the player's cache asynchronously (ZSET and HASH)
-	go func() {
-		if err := pss.CacheClient.UpdatePlayerCache("leaderboard", playerScore); err != nil {
-			pss.Logger.Error("Error updating the cache for player", zap.String("player_id", playerScore.PlayerID), zap.Error(err))
+// WindowBoard returns the board whose scores are those of the window's current period of the given board, so that
+// every read of the service can serve windowed leaderboards. It returns ErrUnknownWindow for windows that are not scheduled.
+func (pss *PlayerScoreService) WindowBoard(b board.Board, w window.Window) (board.Board, error) {
+	if !pss.Windows.Has(w) {
+		return board.Board{}, ErrUnknownWindow
+	}
+	b.ID = pss.Windows.ScopeID(b.ID, w, time.Now())
+	return b, nil
+}
+
+// recordChange appends a score change to the history. Writes that leave the score untouched are not recorded.
+// Failures are only logged, because the score itself has already been stored.
+func (pss *PlayerScoreService) recordChange(boardID, playerID string, change player_score.ScoreChange, origin score_event.Origin) {
+	if !change.Created && change.OldScore == change.NewScore {
+		return
+	}
+
+	event := score_event.ScoreEvent{
+		BoardID:   boardID,
+		PlayerID:  playerID,
+		OldScore:  change.OldScore,
+		NewScore:  change.NewScore,
+		Delta:     change.NewScore - change.OldScore,
+		Created:   change.Created,
+		Source:    origin.Source,
+		RequestID: origin.RequestID,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := pss.HistoryClient.AppendScoreEvent(event); err != nil {
+		pss.Logger.Error("Error appending score event to history", zap.String("player_id", playerID), zap.String("request_id", origin.RequestID), zap.Error(err))
+	}
+}
+
+// GetScoreHistory returns the recorded score changes of a player on the board between from and to, newest first.
+// Zero from and to values leave the time range open on that side.
+func (pss *PlayerScoreService) GetScoreHistory(b board.Board, playerID string, from, to time.Time, limit int64) ([]score_event.ScoreEvent, error) {
+	pss.Logger.Info("GetScoreHistory method called", zap.String("board_id", b.ID), zap.String("player_id", playerID))
+
+	events, err := pss.HistoryClient.GetScoreHistory(b.ID, playerID, from, to, limit)
+	if err != nil {
+		pss.Logger.Error("Error retrieving score history from DB", zap.String("player_id", playerID), zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetTopPlayers retrieves a page of the top players of the board from cache or database, ranked by the board's tie break policy.
+// A cold cached leaderboard, including one that expired or was evicted, is rebuilt first, once for all concurrent
+// readers (see warmLeaderboard), and the database only serves the page when the rebuild fails or takes too long.
+// Players with a profile are shown with its display name.
+// The returned page carries the total number of players and, when there are more, the token of the next page.
+func (pss *PlayerScoreService) GetTopPlayers(ctx context.Context, b board.Board, page player_score.PageRequest) (player_score.Page, error) {
+	pss.Logger.Info("GetTopPlayers method called", zap.String("board_id", b.ID), zap.Int64("offset", page.Offset), zap.Int64("limit", page.Limit))
+
+	tb := b.TieBreak.OrDefault()
+
+	// Fetch one extra entry to find out whether there is a next page
+	query := page
+	if page.Limit > 0 {
+		query.Limit = page.Limit + 1
+	}
+
+	// The position of the first entry is only known up front for offset pages
+	firstPosition := int64(0)
+	if page.After == nil {
+		firstPosition = page.Offset + 1
+	}
+
+	// Attempt to retrieve leaderboard from cache
+	key := cachekey.Leaderboard(b.ID)
+	total, err := pss.CacheClient.GetSetSize(key)
+	if err != nil {
+		pss.Logger.Error("Error retrieving leaderboard size from Cache", zap.Error(err))
+	} else if total == 0 && pss.warmLeaderboard(ctx, b) {
+		// The leaderboard was cold, it is read from the cache once it has been rebuilt
+		total, _ = pss.CacheClient.GetSetSize(key)
+	}
+
+	if total > 0 {
+		leaderboard, err := pss.CacheClient.GetSetByKey(key, tb, query)
+		if err == nil && len(leaderboard) == 0 && pss.leftCache(key) {
+			// The leaderboard expired or was evicted after its size was read, it is served from the database
+			pss.Logger.Info("Leaderboard left the cache while it was read", zap.String("board_id", b.ID))
 		} else {
-			pss.Logger.Info("Player cache updated successfully", zap.String("player_id", playerScore.PlayerID))
+			if err == nil {
+				var ranked []player_score.RankedPlayerScore
+				if ranked, err = pss.cacheRanks(key, b.ID).rankPage(tb, leaderboard, firstPosition); err == nil {
+					// Return leaderboard from cache if available
+					pss.JoinProfiles(ranked)
+					pss.Logger.Info("Cached response provided", zap.Int("count", len(ranked)))
+					return newPage(ranked, total, page.Limit), nil
+				}
+			}
+			pss.Logger.Error("Error retrieving records from Cache", zap.Error(err))
 		}
-	}()
+	}
 
-	pss.Logger.Info(fmt.Sprintf("Create or update operations were successful for player: %v", playerScore))
-	return nil
+	// Cache miss or cache failure, retrieve from database
+	pss.Logger.Info("Cache miss, retrieving from DB")
+
+	if total, err = pss.DBClient.CountPlayers(b.ID); err != nil {
+		pss.Logger.Error("Error counting records in DB", zap.Error(err))
+		return player_score.Page{}, err
+	}
+
+	// Fetch top players from the database
+	leaderboard, err := pss.DBClient.GetTopPlayers(b.ID, tb, query)
+	if err != nil {
+		pss.Logger.Error("Error retrieving records from DB", zap.Error(err))
+		return player_score.Page{}, err
+	}
+
+	ranked, err := pss.dbRanks(b.ID, tb).rankPage(tb, leaderboard, firstPosition)
+	if err != nil {
+		pss.Logger.Error("Error ranking records from DB", zap.Error(err))
+		return player_score.Page{}, err
+	}
+
+	pss.JoinProfiles(ranked)
+	pss.Logger.Info("Top players retrieved from DB", zap.Int("count", len(ranked)))
+	return newPage(ranked, total, page.Limit), nil
 }
 
-// GetTopPlayers retrieves the top players from cache or database.
-func (pss *PlayerScoreService) GetTopPlayers(ctx context.Context) ([]player_score.PlayerScore, error) {
-	pss.Logger.Info("GetTopPlayers method called")
+// leftCache reports whether the cached leaderboard identified by the key is gone, because it expired or was evicted.
+func (pss *PlayerScoreService) leftCache(key string) bool {
+	size, err := pss.CacheClient.GetSetSize(key)
+	return err == nil && size == 0
+}
 
-	// Attempt to retrieve leaderboard from cache
-	leaderboard, err := pss.CacheClient.GetSetByKey("leaderboard")
+// newPage builds the response page from entries fetched with one extra entry beyond the limit.
+// The extra entry only signals that a next page exists and is dropped from the result.
+func newPage(players []player_score.RankedPlayerScore, total, limit int64) player_score.Page {
+	page := player_score.Page{Players: players, Total: total}
+	if limit > 0 && int64(len(players)) > limit {
+		page.Players = players[:limit]
+		page.NextPageToken = player_score.EncodeCursor(player_score.CursorOf(players[limit-1].PlayerScore))
+	}
+	return page
+}
+
+// JoinProfiles replaces the stored names of the leaderboard entries with the display names of their profiles
+// and adds their avatars, countries and regions. Entries of players without a profile keep the name stored with their score.
+// A failed lookup is logged and leaves every entry unchanged, so leaderboards stay available without profiles.
+func (pss *PlayerScoreService) JoinProfiles(players []player_score.RankedPlayerScore) {
+	if len(players) == 0 {
+		return
+	}
+
+	ids := make([]string, len(players))
+	for i, player := range players {
+		ids[i] = player.PlayerID
+	}
+
+	profiles, err := pss.ProfileClient.GetProfiles(ids)
 	if err != nil {
-		pss.Logger.Error("Error retrieving records from Cache", zap.Error(err))
+		pss.Logger.Error("Error retrieving profiles, keeping stored names", zap.Error(err))
+		return
 	}
 
-	// Cache miss, retrieve from database if cache is empty
-	if len(leaderboard) == 0 {
-		pss.Logger.Info("Cache miss, retrieving from DB")
+	byID := make(map[string]profile.Profile, len(profiles))
+	for _, p := range profiles {
+		byID[p.PlayerID] = p
+	}
+	for i := range players {
+		if p, ok := byID[players[i].PlayerID]; ok {
+			players[i].PlayerName = p.DisplayName
+			players[i].AvatarURL = p.AvatarURL
+			players[i].Country = p.Country
+			players[i].Region = p.Region
+		}
+	}
+}
+
+// GetPlayerRank returns the rank, score and percentile of a player on the board under the board's tie break policy.
+// The cached leaderboard is used when it is warm, otherwise the rank is computed by the database.
+func (pss *PlayerScoreService) GetPlayerRank(b board.Board, playerID string) (player_score.PlayerRank, error) {
+	pss.Logger.Info("GetPlayerRank method called", zap.String("board_id", b.ID), zap.String("player_id", playerID))
+
+	tb := b.TieBreak.OrDefault()
+
+	// Attempt to rank the player with the cached leaderboard
+	key := cachekey.Leaderboard(b.ID)
+	if total, err := pss.CacheClient.GetSetSize(key); err == nil && total > 0 {
+		source := pss.cacheRanks(key, b.ID)
+		position, score, err := source.position(playerID)
+		if err == nil {
+			rank, err := source.rankOf(tb, position, score)
+			if err == nil {
+				pss.Logger.Info("Cached rank provided", zap.String("player_id", playerID), zap.Int64("rank", rank))
+				return player_score.NewPlayerRank(playerID, rank, score, total), nil
+			}
+		}
+		pss.Logger.Info("Player missing from cache, ranking from DB", zap.String("player_id", playerID), zap.Error(err))
+	}
+
+	// Cache miss, rank the player with the database
+	source := pss.dbRanks(b.ID, tb)
+	position, score, err := source.position(playerID)
+	if err != nil {
+		pss.Logger.Error("Error fetching player rank from DB", zap.String("player_id", playerID), zap.Error(err))
+		return player_score.PlayerRank{}, err
+	}
 
-		// Fetch top players from the database
-		topPlayers, err := pss.DBClient.GetTopPlayers()
-		if err != nil {
-			pss.Logger.Error("Error retrieving records from DB", zap.Error(err))
+	rank, err := source.rankOf(tb, position, score)
+	if err != nil {
+		pss.Logger.Error("Error fetching player rank from DB", zap.String("player_id", playerID), zap.Error(err))
+		return player_score.PlayerRank{}, err
+	}
+
+	total, err := pss.DBClient.CountPlayers(b.ID)
+	if err != nil {
+		pss.Logger.Error("Error counting records in DB", zap.Error(err))
+		return player_score.PlayerRank{}, err
+	}
+
+	pss.Logger.Info("Player rank retrieved from DB", zap.String("player_id", playerID), zap.Int64("rank", rank))
+	return player_score.NewPlayerRank(playerID, rank, score, total), nil
+}
+
+// GetPlayersAround returns the player's rank together with the slice of the leaderboard centred on the player,
+// holding up to radius players above and below them with their absolute ranks.
+// The cached leaderboard is used when it is warm, otherwise the slice is read from the database.
+func (pss *PlayerScoreService) GetPlayersAround(b board.Board, playerID string, radius int64) (player_score.PlayerRank, []player_score.RankedPlayerScore, error) {
+	pss.Logger.Info("GetPlayersAround method called", zap.String("board_id", b.ID), zap.String("player_id", playerID), zap.Int64("radius", radius))
+
+	tb := b.TieBreak.OrDefault()
+
+	// Attempt to read the window from the cached leaderboard
+	key := cachekey.Leaderboard(b.ID)
+	if total, err := pss.CacheClient.GetSetSize(key); err == nil && total > 0 {
+		if rank, players, err := pss.playersAround(pss.cacheRanks(key, b.ID), tb, playerID, radius, total, func(page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+			return pss.CacheClient.GetSetByKey(key, tb, page)
+		}); err == nil {
+			pss.Logger.Info("Cached response provided", zap.Int("count", len(players)))
+			return rank, players, nil
+		}
+		pss.Logger.Info("Player missing from cache, reading from DB", zap.String("player_id", playerID))
+	}
+
+	// Cache miss, read the window from the database
+	total, err := pss.DBClient.CountPlayers(b.ID)
+	if err != nil {
+		pss.Logger.Error("Error counting records in DB", zap.Error(err))
+		return player_score.PlayerRank{}, nil, err
+	}
+
+	rank, players, err := pss.playersAround(pss.dbRanks(b.ID, tb), tb, playerID, radius, total, func(page player_score.PageRequest) ([]player_score.PlayerScore, error) {
+		return pss.DBClient.GetTopPlayers(b.ID, tb, page)
+	})
+	if err != nil {
+		pss.Logger.Error("Error retrieving players around from DB", zap.String("player_id", playerID), zap.Error(err))
+		return player_score.PlayerRank{}, nil, err
+	}
+
+	pss.Logger.Info("Players around retrieved from DB", zap.Int("count", len(players)))
+	return rank, players, nil
+}
+
+// RankPlayers ranks the given players against each other on the board under the board's tie break policy,
+// e.g. to show a player among their friends. Players without a score on the board are left out.
+// The cached leaderboard is intersected with the players when it is warm, otherwise their scores are read from the database.
+func (pss *PlayerScoreService) RankPlayers(b board.Board, playerIDs []string) ([]player_score.RankedPlayerScore, error) {
+	pss.Logger.Info("RankPlayers method called", zap.String("board_id", b.ID), zap.Int("count", len(playerIDs)))
+
+	tb := b.TieBreak.OrDefault()
+
+	// Attempt to intersect the cached leaderboard with the players
+	var players []player_score.PlayerScore
+	cached := false
+	key := cachekey.Leaderboard(b.ID)
+	if total, err := pss.CacheClient.GetSetSize(key); err == nil && total > 0 {
+		if players, err = pss.CacheClient.GetSetMembers(key, tb, playerIDs); err == nil {
+			pss.Logger.Info("Cached response provided", zap.Int("count", len(players)))
+			cached = true
+		} else {
+			pss.Logger.Error("Error intersecting leaderboard in cache", zap.Error(err))
+		}
+	}
+
+	// Cache miss or cache failure, read the players' scores from the database and order them like the board
+	if !cached {
+		var err error
+		if players, err = pss.DBClient.GetPlayerScores(b.ID, playerIDs); err != nil {
+			pss.Logger.Error("Error retrieving player scores from DB", zap.Error(err))
 			return nil, err
 		}
+		sort.Slice(players, func(i, j int) bool { return tb.Before(players[i], players[j]) })
+		pss.Logger.Info("Player scores retrieved from DB", zap.Int("count", len(players)))
+	}
 
-		pss.Logger.Info("Top players retrieved from DB", zap.Int("count", len(topPlayers)))
+	ranked, err := listRanks(players).rankPage(tb, players, 1)
+	if err != nil {
+		return nil, err
+	}
+	pss.JoinProfiles(ranked)
+	return ranked, nil
+}
 
-		// Cache the leaderboard asynchronously
-		go func() {
-			for _, player := range topPlayers {
-				if err := pss.CacheClient.InsertRecord("leaderboard", player.PlayerID, player.PlayerName, float64(player.Score)); err != nil {
-					pss.Logger.Error("Error inserting new records into cache", zap.String("player_id", player.PlayerID), zap.Error(err))
-				} else {
-					pss.Logger.Info("Player score cached successfully", zap.String("player_id", player.PlayerID))
-				}
-			}
-		}()
+// playersAround reads the window around the player from one source, fetching pages with readPage.
+func (pss *PlayerScoreService) playersAround(source rankSource, tb player_score.TieBreak, playerID string, radius, total int64, readPage func(player_score.PageRequest) ([]player_score.PlayerScore, error)) (player_score.PlayerRank, []player_score.RankedPlayerScore, error) {
+	position, score, err := source.position(playerID)
+	if err != nil {
+		return player_score.PlayerRank{}, nil, err
+	}
 
-		return topPlayers, nil
+	rank, err := source.rankOf(tb, position, score)
+	if err != nil {
+		return player_score.PlayerRank{}, nil, err
+	}
+
+	page := aroundPage(position, radius)
+	players, err := readPage(page)
+	if err != nil {
+		return player_score.PlayerRank{}, nil, err
+	}
+
+	ranked, err := source.rankPage(tb, players, page.Offset+1)
+	if err != nil {
+		return player_score.PlayerRank{}, nil, err
+	}
+	pss.JoinProfiles(ranked)
+	return player_score.NewPlayerRank(playerID, rank, score, total), ranked, nil
+}
+
+// aroundPage returns the page holding up to radius entries on each side of the given position.
+func aroundPage(position, radius int64) player_score.PageRequest {
+	offset := position - 1 - radius
+	if offset < 0 {
+		offset = 0
+	}
+	return player_score.PageRequest{Offset: offset, Limit: position + radius - offset}
+}
+
+// ResetScores removes every live score of the board from the database and drops its cached leaderboards, groups included.
+// The attribute leaderboards mirror the board, so they are emptied as well. Rebuilds running concurrently are
+// invalidated before the cache is dropped, so they cannot bring the removed scores back.
+// The windowed leaderboards and the score history of the board are kept.
+func (pss *PlayerScoreService) ResetScores(b board.Board) error {
+	pss.Logger.Info("ResetScores method called", zap.String("board_id", b.ID))
+
+	if err := pss.DBClient.DeleteScores(b.ID); err != nil {
+		pss.Logger.Error("Error deleting scores from DB", zap.String("board_id", b.ID), zap.Error(err))
+		return err
+	}
+
+	if err := pss.resetAttributeScores(b); err != nil {
+		return err
 	}
 
-	// Return leaderboard from cache if available
-	pss.Logger.Info("Cached response provided", zap.Int("count", len(leaderboard)))
-	return leaderboard, nil
+	if err := pss.dropLeaderboards(b); err != nil {
+		return err
+	}
+
+	pss.Logger.Info("Scores reset successfully", zap.String("board_id", b.ID))
+	return nil
 }
 
-// GetPlayerScore fetches a player's score from the database.
-func (pss *PlayerScoreService) GetPlayerScore(playerID string) (int, error) {
-	pss.Logger.Info("GetPlayerScore method called", zap.String("player_id", playerID))
+// dropLeaderboards deletes the cached leaderboard, group leaderboard and attribute leaderboards of the board after
+// their scores were removed from the database, and invalidates the rebuilds running for them, so they are rebuilt
+// from the remaining scores.
+func (pss *PlayerScoreService) dropLeaderboards(b board.Board) error {
+	if err := invalidateRebuilds(pss.CacheClient, b.ID); err != nil {
+		pss.Logger.Error("Error invalidating leaderboard rebuilds", zap.String("board_id", b.ID), zap.Error(err))
+		return err
+	}
 
-	// Retrieve player score from the database
-	score, err := pss.DBClient.GetPlayerScore(playerID)
+	for _, key := range []string{cachekey.Leaderboard(b.ID), cachekey.GroupLeaderboard(b.ID)} {
+		if err := pss.CacheClient.DeleteKey(key); err != nil {
+			pss.Logger.Error("Error deleting leaderboard from cache", zap.String("board_id", b.ID), zap.String("key", key), zap.Error(err))
+			return err
+		}
+	}
+
+	for _, a := range profile.Attributes {
+		if err := pss.CacheClient.DeleteKeysWithPrefix(cachekey.Leaderboard(profile.ScopePrefix(b.ID, a))); err != nil {
+			pss.Logger.Error("Error deleting attribute leaderboards from cache", zap.String("board_id", b.ID), zap.String("attribute", string(a)), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPlayerScore fetches a player's score on the board, reporting whether it was served from cache.
+// The cached leaderboard is read first. On a miss the score is read from the database and written back to the cache
+// without replacing a concurrent update, or the whole leaderboard is warmed when it is cold. Players unknown to the
+// database are remembered for missingPlayerTTL, so polling for them does not reach the database on every request.
+func (pss *PlayerScoreService) GetPlayerScore(b board.Board, playerID string) (int, bool, error) {
+	pss.Logger.Info("GetPlayerScore method called", zap.String("board_id", b.ID), zap.String("player_id", playerID))
+
+	tb := b.TieBreak.OrDefault()
+
+	// Attempt to read the player's entry from the cached leaderboard
+	key := cachekey.Leaderboard(b.ID)
+	if player, err := pss.CacheClient.GetRecordByKey(key, tb, playerID); err == nil {
+		pss.Logger.Info("Cached player score provided", zap.String("player_id", playerID), zap.Int("score", player.Score))
+		return player.Score, true, nil
+	}
+
+	missingKey := cachekey.MissingPlayer(b.ID, playerID)
+	if missing, err := pss.CacheClient.HasMarker(missingKey); err == nil && missing {
+		pss.Logger.Info("Player cached as missing", zap.String("player_id", playerID))
+		return 0, true, ErrPlayerNotFound
+	}
+
+	// Cache miss, retrieve the player score from the database
+	players, err := pss.DBClient.GetPlayerScores(b.ID, []string{playerID})
 	if err != nil {
 		pss.Logger.Error("Error fetching player score from DB", zap.String("player_id", playerID), zap.Error(err))
-		return 0, err
+		return 0, false, err
+	}
+	if len(players) == 0 {
+		if err := pss.CacheClient.SetMarker(missingKey, missingPlayerTTL); err != nil {
+			pss.Logger.Error("Error caching missing player", zap.String("player_id", playerID), zap.Error(err))
+		}
+		pss.Logger.Info("Player not found in DB", zap.String("player_id", playerID))
+		return 0, false, ErrPlayerNotFound
 	}
+	player := players[0]
 
-	pss.Logger.Info("Player score retrieved successfully", zap.String("player_id", playerID), zap.Int("score", score))
-	return score, nil
+	// Write the player back to a warm leaderboard, a cold one is warmed as a whole
+	if size, err := pss.CacheClient.GetSetSize(key); err == nil && size == 0 {
+		go pss.warmLeaderboard(pss.CTX, b)
+	} else if err == nil {
+		if err := pss.CacheClient.FillPlayerCache(key, tb, player); err != nil {
+			pss.Logger.Error("Error filling the cache for player", zap.String("player_id", playerID), zap.Error(err))
+		}
+	}
+
+	pss.Logger.Info("Player score retrieved from DB", zap.String("player_id", playerID), zap.Int("score", player.Score))
+	return player.Score, false, nil
+}
+
+// forgetMissing drops the negative cache entries a new score on the board makes stale: the marker of the player who
+// just got the score, and the marker of the board's leaderboard if a rebuild found it empty. Failures are only logged,
+// the entries expire after missingPlayerTTL and emptyLeaderboardTTL anyway.
+func (pss *PlayerScoreService) forgetMissing(boardID, playerID string) {
+	if err := pss.CacheClient.DeleteKey(cachekey.MissingPlayer(boardID, playerID)); err != nil {
+		pss.Logger.Error("Error deleting missing player from cache", zap.String("board_id", boardID), zap.String("player_id", playerID), zap.Error(err))
+	}
+	if err := pss.CacheClient.DeleteKey(cachekey.EmptyLeaderboard(boardID)); err != nil {
+		pss.Logger.Error("Error deleting empty leaderboard marker from cache", zap.String("board_id", boardID), zap.Error(err))
+	}
 }
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/window"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAddOrUpdatePlayerScoreUpdatePolicies(t *testing.T) {
+	tests := []struct {
+		up     player_score.UpdatePolicy
+		scores []int  // Scores submitted in order
+		kept   []bool // Whether each submission replaced the stored score
+		want   int    // Score stored and cached in the end
+	}{
+		{player_score.UpdateKeepLatest, []int{10, 5, 20, 15}, []bool{true, true, true, true}, 15},
+		{player_score.UpdateKeepBest, []int{10, 5, 20, 15}, []bool{true, false, true, false}, 20},
+		{player_score.UpdateKeepLowest, []int{10, 5, 20, 3}, []bool{true, true, false, true}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.up), func(t *testing.T) {
+			ts := newTestServices(t)
+			b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, tt.up)
+			if _, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: "other", Score: 1}, testOrigin); err != nil {
+				t.Fatalf("AddOrUpdatePlayerScore error = %v", err)
+			}
+			ts.warm(t, b)
+
+			for i, score := range tt.scores {
+				change, err := ts.Scores.AddOrUpdatePlayerScore(b, player_score.PlayerScore{PlayerID: "p1", Score: score}, testOrigin)
+				if err != nil {
+					t.Fatalf("AddOrUpdatePlayerScore(%d) error = %v", score, err)
+				}
+				if change.Replaced != tt.kept[i] {
+					t.Errorf("AddOrUpdatePlayerScore(%d) replaced = %v, want %v", score, change.Replaced, tt.kept[i])
+				}
+			}
+
+			if stored, _ := ts.storedScore(t, b.ID, "p1"); stored != tt.want {
+				t.Errorf("stored score = %d, want %d", stored, tt.want)
+			}
+			if cached, _ := ts.cachedScore(t, b.ID, "p1"); cached != tt.want {
+				t.Errorf("cached score = %d, want %d", cached, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncrementPlayerScoreBoundsEveryScopeOnItsOwn(t *testing.T) {
+	ts := newTestServices(t)
+	b := ts.createBoard(t, "quiz", player_score.TieBreakEarliest, player_score.UpdateKeepLatest)
+	ts.Scores.Windows = window.Schedule{Windows: []window.Window{window.Daily}, Location: time.UTC}
+	daily := ts.Scores.Windows.ScopeID(b.ID, window.Daily, time.Now())
+
+	// The player reached the ceiling on the board before today
+	reached := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ts.writeScores(t, b.ID, player_score.PlayerScore{PlayerID: "p1", Score: 100, AchievedAt: reached})
+	ts.Relay.relayPending()
+
+	ceiling := 100
+	tests := []struct {
+		delta     int
+		board     int      // Board score afterwards
+		daily     int      // Daily score afterwards
+		replaced  bool     // Whether the board score changed
+		changedIn []string // Scopes that get an outbox entry
+	}{
+		{10, 100, 10, false, []string{daily}},
+		{95, 100, 100, false, []string{daily}},
+		{5, 100, 100, false, nil},
+		{-30, 70, 70, true, []string{b.ID, daily}},
+	}
+	for _, tt := range tests {
+		change, err := ts.Scores.IncrementPlayerScore(b, player_score.Increment{PlayerID: "p1", Delta: tt.delta, Ceiling: &ceiling}, testOrigin)
+		if err != nil {
+			t.Fatalf("IncrementPlayerScore(%d) error = %v", tt.delta, err)
+		}
+		if change.Replaced != tt.replaced || change.NewScore != tt.board {
+			t.Errorf("IncrementPlayerScore(%d) = %+v, want board score %d replaced %v", tt.delta, change, tt.board, tt.replaced)
+		}
+		if score, _ := ts.storedScore(t, b.ID, "p1"); score != tt.board {
+			t.Errorf("after %d: board score = %d, want %d", tt.delta, score, tt.board)
+		}
+		if players, _ := ts.DB.GetPlayerScores(b.ID, []string{"p1"}); !tt.replaced && !players[0].AchievedAt.Equal(reached) {
+			t.Errorf("after %d: board score reached at %v, want it kept at %v", tt.delta, players[0].AchievedAt, reached)
+		}
+		if score, _ := ts.storedScore(t, daily, "p1"); score != tt.daily {
+			t.Errorf("after %d: daily score = %d, want %d", tt.delta, score, tt.daily)
+		}
+
+		pending, err := ts.DB.GetPendingEntries(time.Now(), 100)
+		if err != nil {
+			t.Fatalf("GetPendingEntries error = %v", err)
+		}
+		var scopes []string
+		for _, entry := range pending {
+			scopes = append(scopes, entry.ScopeID)
+		}
+		if !reflect.DeepEqual(scopes, tt.changedIn) {
+			t.Errorf("after %d: outbox entries for %v, want %v", tt.delta, scopes, tt.changedIn)
+		}
+		ts.Relay.relayPending()
+	}
+
+	events, err := ts.Scores.GetScoreHistory(b, "p1", time.Time{}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("GetScoreHistory error = %v", err)
+	}
+	if len(events) != 1 || events[0].NewScore != 70 {
+		t.Errorf("history = %+v, want only the change to 70", events)
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/outbox"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// errScoreStreamLockLost is returned by followLocked when the worker lost the score stream lock while following the stream.
+var errScoreStreamLockLost = errors.New("the score stream lock was lost")
+
+const (
+	scoreStreamName       = "scores"         // Name under which the worker stores its resume token
+	scoreStreamMinBackoff = time.Second      // Delay before the stream is reopened after the first failure
+	scoreStreamMaxBackoff = 30 * time.Second // Longest delay before the stream is reopened after repeated failures
+	scoreStreamLockTTL    = 30 * time.Second // Longest time a crashed instance keeps the others from following the stream, renewed while following
+	scoreStreamLockRetry  = 10 * time.Second // Interval at which instances without the lock check whether they can take it over
+)
+
+type ScoreStreamWorker struct {
+	StreamClient repositories.IScoreStreamRepository // Interface for following the changes of the stored scores
+	BoardClient  repositories.IBoardRepository       // Interface for reading the tie break policy of the boards
+	CacheClient  repositories.ICacheRepository       // Interface for the cached leaderboards the changes are applied to
+	OutboxClient repositories.IOutboxRepository      // Interface for reporting the changes that cannot be applied as dead outbox entries
+	CTX          context.Context                     // Context stopping the worker when it is cancelled
+	Logger       *zap.Logger                         // Logger for structured logging
+	boards       map[string]board.Board              // Boards read during the current batch of changes, a recreated board is read again in the next batch
+	resumeToken  []byte                              // Resume token stored last, an unchanged token is not stored again
+}
+
+// NewScoreStreamWorker initializes a new ScoreStreamWorker with the provided stream, board, cache and outbox clients, context, and logger.
+func NewScoreStreamWorker(stream_client repositories.IScoreStreamRepository, board_client repositories.IBoardRepository, cache_client repositories.ICacheRepository, outbox_client repositories.IOutboxRepository, ctx context.Context, custom_logger *zap.Logger) *ScoreStreamWorker {
+	return &ScoreStreamWorker{
+		StreamClient: stream_client,
+		BoardClient:  board_client,
+		CacheClient:  cache_client,
+		OutboxClient: outbox_client,
+		CTX:          ctx,
+		Logger:       custom_logger,
+		boards:       make(map[string]board.Board),
+	}
+}
+
+// Start follows the changes of the stored scores in the background until the worker's context is cancelled, and
+// applies them to the cached leaderboards and player details. This brings scores written directly to the database
+// by other applications into the cache. The worker continues after the resume token it stored last, so no change is
+// missed across restarts; a change may be applied twice, which is harmless since it is copied as a whole.
+// Only one instance follows the stream at a time, coordinated through the score stream lock like the outbox relay;
+// the others check every scoreStreamLockRetry whether they can take over. When the stream fails it is reopened with
+// exponential backoff.
+func (w *ScoreStreamWorker) Start() {
+	go func() {
+		backoff := scoreStreamMinBackoff
+		for {
+			followed, err := w.followLocked()
+			if w.CTX.Err() != nil {
+				return
+			}
+
+			switch {
+			case err == nil && !followed:
+				// Another instance follows the stream
+				select {
+				case <-w.CTX.Done():
+					return
+				case <-time.After(scoreStreamLockRetry):
+				}
+				continue
+			case errors.Is(err, errScoreStreamLockLost):
+				w.Logger.Info("Score stream lock lost, another instance may follow the stream now")
+				continue
+			case errors.Is(err, repositories.ErrResumeTokenLost):
+				// The changes since the token are gone, continue from now and leave the gap to a cache rebuild
+				w.Logger.Error("Score stream resume token lost, continuing from now, the cache may need a rebuild")
+				if err := w.StreamClient.SaveResumeToken(scoreStreamName, nil); err != nil {
+					w.Logger.Error("Error removing lost resume token", zap.Error(err))
+				}
+				continue
+			}
+
+			w.Logger.Error("Score stream failed, reopening it", zap.Duration("backoff", backoff), zap.Error(err))
+			select {
+			case <-w.CTX.Done():
+				return
+			case <-time.After(backoff):
+			}
+			if backoff *= 2; backoff > scoreStreamMaxBackoff {
+				backoff = scoreStreamMaxBackoff
+			}
+		}
+	}()
+}
+
+// followLocked takes the score stream lock and follows the stream while it holds the lock, renewing it as it goes.
+// It reports false when another instance holds the lock, and errScoreStreamLockLost when the lock was lost while
+// following the stream.
+func (w *ScoreStreamWorker) followLocked() (bool, error) {
+	token := lockToken()
+	acquired, err := w.CacheClient.AcquireLock(cachekey.ScoreStreamLock, token, scoreStreamLockTTL)
+	if err != nil || !acquired {
+		return false, err
+	}
+
+	held, stop := holdLock(w.CTX, w.CacheClient, w.Logger, cachekey.ScoreStreamLock, token, scoreStreamLockTTL)
+	defer func() {
+		stop()
+		if err := w.CacheClient.ReleaseLock(cachekey.ScoreStreamLock, token); err != nil {
+			w.Logger.Error("Error releasing the score stream lock", zap.Error(err))
+		}
+	}()
+
+	err = w.follow(held)
+	if held.Err() != nil && w.CTX.Err() == nil {
+		return true, errScoreStreamLockLost
+	}
+	return true, err
+}
+
+// follow opens the score stream after the stored resume token and applies its changes until the stream fails or
+// ctx is done.
+func (w *ScoreStreamWorker) follow(ctx context.Context) error {
+	token, err := w.StreamClient.GetResumeToken(scoreStreamName)
+	if err != nil {
+		return err
+	}
+	w.resumeToken = token
+
+	w.Logger.Info("Following the score stream", zap.Bool("resuming", token != nil))
+	return w.StreamClient.WatchScores(ctx, token, func(change repositories.ScoreDocumentChange) error {
+		if err := w.apply(change); err != nil {
+			w.Logger.Error("Error applying score change to the cache", zap.String("scope_id", change.ScopeID), zap.String("player_id", change.Player.PlayerID), zap.Error(err))
+			return err
+		}
+		return nil
+	}, w.checkpoint)
+}
+
+// checkpoint stores the resume token reached after a batch of changes, or after a poll without changes, and forgets
+// the boards read during the batch. Changes applied after it are applied again after a restart.
+func (w *ScoreStreamWorker) checkpoint(resumeToken []byte) error {
+	w.boards = make(map[string]board.Board)
+	if bytes.Equal(resumeToken, w.resumeToken) {
+		return nil
+	}
+	if err := w.StreamClient.SaveResumeToken(scoreStreamName, resumeToken); err != nil {
+		return err
+	}
+	w.resumeToken = resumeToken
+	return nil
+}
+
+// apply copies a changed score to the cached leaderboard of its scope, or removes a deleted one from it.
+// Player details are kept on deletes, since they are shared by every leaderboard. Changes of scores whose board does
+// not exist, such as scores written without a board ID, are reported as dead outbox entries: they can be inspected
+// and retried once the board exists.
+func (w *ScoreStreamWorker) apply(change repositories.ScoreDocumentChange) error {
+	key := cachekey.Leaderboard(change.ScopeID)
+	if change.Deleted {
+		return w.CacheClient.RemoveMember(key, change.Player.PlayerID)
+	}
+
+	boardID, _ := window.SplitScopeID(change.ScopeID)
+	b, ok := w.boards[boardID]
+	if !ok {
+		var err error
+		b, err = w.BoardClient.GetBoard(boardID)
+		if errors.Is(err, repositories.ErrBoardNotFound) {
+			w.Logger.Error("Reporting score change of an unknown board as a dead outbox entry", zap.String("scope_id", change.ScopeID), zap.String("player_id", change.Player.PlayerID))
+			return w.OutboxClient.RecordDeadEntry(outbox.NewDeadEntry(change.ScopeID, change.Player.PlayerID, err, time.Now().UTC()))
+		}
+		if err != nil {
+			return err
+		}
+		w.boards[boardID] = b
+	}
+
+	return w.CacheClient.UpdatePlayerCache(key, b.TieBreak.OrDefault(), player_score.UpdateKeepLatest, change.Player)
+}
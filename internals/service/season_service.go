@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/season"
+	"quiz/internals/repositories"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidSeasonID = errors.New("season id must be 1-64 characters of letters, digits, '-' or '_'") // Returned when a season ID is empty or contains unsupported characters
+	ErrSeasonNotActive = errors.New("season is not active")                                             // Returned when ending a season that has already ended
+)
+
+type SeasonService struct {
+	SeasonClient    repositories.ISeasonRepository    // Interface for season storage operations
+	StandingsClient repositories.IStandingsRepository // Interface for the archived final standings
+	Scores          *PlayerScoreService               // Service reading and resetting the live leaderboards
+	CTX             context.Context                   // Context for managing request-scoped values
+	Logger          *zap.Logger                       // Logger for structured logging
+}
+
+// NewSeasonService initializes a new SeasonService with the provided season and standings repositories, player score service, context, and logger.
+func NewSeasonService(season_client repositories.ISeasonRepository, standings_client repositories.IStandingsRepository, scores *PlayerScoreService, ctx context.Context, custom_logger *zap.Logger) *SeasonService {
+	return &SeasonService{
+		SeasonClient:    season_client,
+		StandingsClient: standings_client,
+		Scores:          scores,
+		CTX:             ctx,
+		Logger:          custom_logger,
+	}
+}
+
+// StartSeason starts a new season on the board. A board has at most one active season at a time.
+func (ss *SeasonService) StartSeason(b board.Board, seasonID, name string) (season.Season, error) {
+	ss.Logger.Info("StartSeason method called", zap.String("board_id", b.ID), zap.String("season_id", seasonID))
+
+	if !boardIDPattern.MatchString(seasonID) {
+		return season.Season{}, ErrInvalidSeasonID
+	}
+
+	s := season.Season{BoardID: b.ID, ID: seasonID, Name: name, Active: true, StartedAt: time.Now().UTC()}
+	if err := ss.SeasonClient.CreateSeason(s); err != nil {
+		ss.Logger.Error("Error creating season", zap.String("board_id", b.ID), zap.String("season_id", seasonID), zap.Error(err))
+		return season.Season{}, err
+	}
+
+	ss.Logger.Info("Season started successfully", zap.String("board_id", b.ID), zap.String("season_id", seasonID))
+	return s, nil
+}
+
+// GetSeasons lists every season of the board, newest first.
+func (ss *SeasonService) GetSeasons(b board.Board) ([]season.Season, error) {
+	ss.Logger.Info("GetSeasons method called", zap.String("board_id", b.ID))
+
+	seasons, err := ss.SeasonClient.GetSeasons(b.ID)
+	if err != nil {
+		ss.Logger.Error("Error retrieving seasons from DB", zap.String("board_id", b.ID), zap.Error(err))
+		return nil, err
+	}
+	return seasons, nil
+}
+
+// GetSeason retrieves a single season of the board.
+func (ss *SeasonService) GetSeason(b board.Board, seasonID string) (season.Season, error) {
+	return ss.SeasonClient.GetSeason(b.ID, seasonID)
+}
+
+// EndSeason ends the active season of the board. The live leaderboard is archived as the season's final standings,
+// keeping the final ranks, and the archived scores are removed from the board and its attribute leaderboards
+// afterwards. Scores submitted while the archive is taken are kept, so they count for the next season.
+// If archiving fails the season stays active and ending it again starts the archive over.
+func (ss *SeasonService) EndSeason(b board.Board, seasonID string) (season.Season, error) {
+	ss.Logger.Info("EndSeason method called", zap.String("board_id", b.ID), zap.String("season_id", seasonID))
+
+	s, err := ss.SeasonClient.GetSeason(b.ID, seasonID)
+	if err != nil {
+		return season.Season{}, err
+	}
+	if !s.Active {
+		return season.Season{}, ErrSeasonNotActive
+	}
+
+	total, err := archiveLeaderboard(ss.CTX, ss.Scores, ss.StandingsClient, b, s.ArchiveID())
+	if err != nil {
+		ss.Logger.Error("Error archiving season standings", zap.String("board_id", b.ID), zap.String("season_id", seasonID), zap.Error(err))
+		return season.Season{}, err
+	}
+
+	endedAt := time.Now().UTC()
+	if err := ss.SeasonClient.EndSeason(b.ID, seasonID, endedAt, total); err != nil {
+		ss.Logger.Error("Error ending season", zap.String("board_id", b.ID), zap.String("season_id", seasonID), zap.Error(err))
+		return season.Season{}, err
+	}
+	s.Active, s.EndedAt, s.Total = false, &endedAt, total
+
+	if err := resetArchivedScores(ss.Scores, ss.StandingsClient, b, s.ArchiveID()); err != nil {
+		ss.Logger.Error("Error removing archived scores", zap.String("board_id", b.ID), zap.String("season_id", seasonID), zap.Error(err))
+		return season.Season{}, err
+	}
+
+	ss.Logger.Info("Season ended successfully", zap.String("board_id", b.ID), zap.String("season_id", seasonID), zap.Int64("total", total))
+	return s, nil
+}
+
+// GetSeasonTopPlayers returns a page of the final standings of an ended season with the players' final ranks.
+// Players with a profile are shown with its current display name.
+// For the active season the live leaderboard of the board is returned.
+func (ss *SeasonService) GetSeasonTopPlayers(ctx context.Context, b board.Board, seasonID string, page player_score.PageRequest) (player_score.Page, error) {
+	ss.Logger.Info("GetSeasonTopPlayers method called", zap.String("board_id", b.ID), zap.String("season_id", seasonID))
+
+	s, err := ss.SeasonClient.GetSeason(b.ID, seasonID)
+	if err != nil {
+		return player_score.Page{}, err
+	}
+	if s.Active {
+		return ss.Scores.GetTopPlayers(ctx, b, page)
+	}
+
+	standings, err := readArchive(ss.StandingsClient, b.ID, s.ArchiveID(), s.Total, page)
+	if err != nil {
+		ss.Logger.Error("Error retrieving season standings from DB", zap.String("board_id", b.ID), zap.String("season_id", seasonID), zap.Error(err))
+		return player_score.Page{}, err
+	}
+	ss.Scores.JoinProfiles(standings.Players)
+	return standings, nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/score_event"
+	"quiz/internals/domain/window"
+	"quiz/internals/repositories"
+	"quiz/internals/repositories/cachekey"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testOrigin is the origin of the score changes made by tests.
+var testOrigin = score_event.Origin{Source: "test", RequestID: "test"}
+
+// testServices holds a PlayerScoreService and its relay wired to the in-memory repositories, the relay is not started
+// so tests decide when the outbox is applied.
+type testServices struct {
+	DB     *repositories.MemoryDBClient
+	Cache  *repositories.MemoryCacheClient
+	Scores *PlayerScoreService
+	Relay  *OutboxRelay
+}
+
+// newTestServices returns services backed by new in-memory repositories, without windows.
+func newTestServices(t *testing.T) testServices {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	db, cache, logger := repositories.NewMemoryDBClient(), repositories.NewMemoryCacheClient(), zap.NewNop()
+	relay := NewOutboxRelay(db, db, db, cache, ctx, logger)
+	groups := NewGroupService(db, db, cache, ctx, logger)
+	scores := NewPlayerScoreService(db, cache, db, window.Schedule{Location: time.UTC}, groups, db, relay, time.Second, ctx, logger)
+	return testServices{DB: db, Cache: cache, Scores: scores, Relay: relay}
+}
+
+// createBoard stores a board with the given policies.
+func (ts testServices) createBoard(t *testing.T, id string, tb player_score.TieBreak, up player_score.UpdatePolicy) board.Board {
+	t.Helper()
+	b := board.Board{ID: id, Name: id, TieBreak: tb, UpdatePolicy: up}
+	if err := ts.DB.CreateBoard(b); err != nil {
+		t.Fatalf("CreateBoard(%q) error = %v", id, err)
+	}
+	return b
+}
+
+// cachedScore returns the score of the player in the cached leaderboard of the scope, and whether the player is in it.
+func (ts testServices) cachedScore(t *testing.T, scopeID, playerID string) (int, bool) {
+	t.Helper()
+	_, score, err := ts.Cache.GetRank(cachekey.Leaderboard(scopeID), playerID)
+	if err != nil {
+		return 0, false
+	}
+	return score, true
+}
+
+// storedScore returns the score of the player on the board in the database, and whether the player has one.
+func (ts testServices) storedScore(t *testing.T, scopeID, playerID string) (int, bool) {
+	t.Helper()
+	players, err := ts.DB.GetPlayerScores(scopeID, []string{playerID})
+	if err != nil {
+		t.Fatalf("GetPlayerScores error = %v", err)
+	}
+	if len(players) == 0 {
+		return 0, false
+	}
+	return players[0].Score, true
+}
+
+// warm fills the cached leaderboard of the board from the database, the way a read of a cold leaderboard does.
+func (ts testServices) warm(t *testing.T, b board.Board) {
+	t.Helper()
+	if _, err := ts.Scores.RebuildBoard(b); err != nil {
+		t.Fatalf("RebuildBoard(%q) error = %v", b.ID, err)
+	}
+}
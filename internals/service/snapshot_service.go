@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/snapshot"
+	"quiz/internals/domain/standing"
+	"quiz/internals/repositories"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidSnapshotName = errors.New("snapshot name must be 1-64 characters of letters, digits, '-' or '_'") // Returned when a snapshot name is empty or contains unsupported characters
+	ErrSnapshotIncomplete  = errors.New("snapshot is still being taken")                                        // Returned when reading a snapshot whose players are not all archived yet
+)
+
+type SnapshotService struct {
+	SnapshotClient  repositories.ISnapshotRepository  // Interface for snapshot storage operations
+	StandingsClient repositories.IStandingsRepository // Interface for the archived ranked players
+	Scores          *PlayerScoreService               // Service reading the live leaderboards
+	CTX             context.Context                   // Context for managing request-scoped values
+	Logger          *zap.Logger                       // Logger for structured logging
+}
+
+// NewSnapshotService initializes a new SnapshotService with the provided snapshot and standings repositories, player score service, context, and logger.
+func NewSnapshotService(snapshot_client repositories.ISnapshotRepository, standings_client repositories.IStandingsRepository, scores *PlayerScoreService, ctx context.Context, custom_logger *zap.Logger) *SnapshotService {
+	return &SnapshotService{
+		SnapshotClient:  snapshot_client,
+		StandingsClient: standings_client,
+		Scores:          scores,
+		CTX:             ctx,
+		Logger:          custom_logger,
+	}
+}
+
+// TakeSnapshot captures the current ranking of the board under the given name. The name is claimed first,
+// so concurrent snapshots cannot overwrite each other, and released again if archiving the ranking fails.
+func (ss *SnapshotService) TakeSnapshot(ctx context.Context, b board.Board, name string) (snapshot.Snapshot, error) {
+	ss.Logger.Info("TakeSnapshot method called", zap.String("board_id", b.ID), zap.String("name", name))
+
+	if !boardIDPattern.MatchString(name) {
+		return snapshot.Snapshot{}, ErrInvalidSnapshotName
+	}
+
+	s := snapshot.Snapshot{BoardID: b.ID, Name: name, TakenAt: time.Now().UTC()}
+	if err := ss.SnapshotClient.CreateSnapshot(s); err != nil {
+		ss.Logger.Error("Error creating snapshot", zap.String("board_id", b.ID), zap.String("name", name), zap.Error(err))
+		return snapshot.Snapshot{}, err
+	}
+
+	total, err := archiveLeaderboard(ctx, ss.Scores, ss.StandingsClient, b, s.ArchiveID())
+	if err == nil {
+		err = ss.SnapshotClient.CompleteSnapshot(b.ID, name, total)
+	}
+	if err != nil {
+		ss.Logger.Error("Error archiving snapshot", zap.String("board_id", b.ID), zap.String("name", name), zap.Error(err))
+		ss.release(b.ID, s)
+		return snapshot.Snapshot{}, err
+	}
+	s.Complete, s.Total = true, total
+
+	ss.Logger.Info("Snapshot taken successfully", zap.String("board_id", b.ID), zap.String("name", name), zap.Int64("total", total))
+	return s, nil
+}
+
+// GetSnapshots lists every snapshot of the board, newest first.
+func (ss *SnapshotService) GetSnapshots(b board.Board) ([]snapshot.Snapshot, error) {
+	ss.Logger.Info("GetSnapshots method called", zap.String("board_id", b.ID))
+
+	snapshots, err := ss.SnapshotClient.GetSnapshots(b.ID)
+	if err != nil {
+		ss.Logger.Error("Error retrieving snapshots from DB", zap.String("board_id", b.ID), zap.Error(err))
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// GetSnapshot retrieves a single snapshot of the board.
+func (ss *SnapshotService) GetSnapshot(b board.Board, name string) (snapshot.Snapshot, error) {
+	return ss.SnapshotClient.GetSnapshot(b.ID, name)
+}
+
+// DeleteSnapshot removes a snapshot of the board together with its archived players.
+func (ss *SnapshotService) DeleteSnapshot(b board.Board, name string) error {
+	ss.Logger.Info("DeleteSnapshot method called", zap.String("board_id", b.ID), zap.String("name", name))
+
+	s, err := ss.SnapshotClient.GetSnapshot(b.ID, name)
+	if err != nil {
+		return err
+	}
+	return ss.release(b.ID, s)
+}
+
+// release removes the snapshot and its archived players.
+func (ss *SnapshotService) release(boardID string, s snapshot.Snapshot) error {
+	if err := ss.StandingsClient.DeleteStandings(boardID, s.ArchiveID()); err != nil {
+		ss.Logger.Error("Error deleting snapshot standings", zap.String("board_id", boardID), zap.String("name", s.Name), zap.Error(err))
+		return err
+	}
+	if err := ss.SnapshotClient.DeleteSnapshot(boardID, s.Name); err != nil {
+		ss.Logger.Error("Error deleting snapshot", zap.String("board_id", boardID), zap.String("name", s.Name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetSnapshotTopPlayers returns a page of the ranked players of a snapshot, with the ranks they had when it was taken.
+// Players with a profile are shown with its current display name.
+func (ss *SnapshotService) GetSnapshotTopPlayers(b board.Board, name string, page player_score.PageRequest) (player_score.Page, error) {
+	ss.Logger.Info("GetSnapshotTopPlayers method called", zap.String("board_id", b.ID), zap.String("name", name))
+
+	s, err := ss.completeSnapshot(b.ID, name)
+	if err != nil {
+		return player_score.Page{}, err
+	}
+
+	standings, err := readArchive(ss.StandingsClient, b.ID, s.ArchiveID(), s.Total, page)
+	if err != nil {
+		ss.Logger.Error("Error retrieving snapshot standings from DB", zap.String("board_id", b.ID), zap.String("name", name), zap.Error(err))
+		return player_score.Page{}, err
+	}
+	ss.Scores.JoinProfiles(standings.Players)
+	return standings, nil
+}
+
+// DiffSnapshots compares two snapshots of the board and returns the rank movement of every player in either of them.
+// Players of the later snapshot come first in its rank order, followed by the players that dropped out of it.
+// The earlier snapshot is held in memory while the later one is streamed.
+func (ss *SnapshotService) DiffSnapshots(b board.Board, from, to string) ([]snapshot.Movement, error) {
+	ss.Logger.Info("DiffSnapshots method called", zap.String("board_id", b.ID), zap.String("from", from), zap.String("to", to))
+
+	fromSnapshot, err := ss.completeSnapshot(b.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	toSnapshot, err := ss.completeSnapshot(b.ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index the earlier snapshot by player, remembering its order for the dropped players
+	earlier := make(map[string]standing.Standing, fromSnapshot.Total)
+	order := make([]string, 0, fromSnapshot.Total)
+	err = forEachStanding(ss.StandingsClient, b.ID, fromSnapshot.ArchiveID(), func(entry standing.Standing) {
+		earlier[entry.PlayerID] = entry
+		order = append(order, entry.PlayerID)
+	})
+	if err != nil {
+		ss.Logger.Error("Error retrieving snapshot standings from DB", zap.String("board_id", b.ID), zap.String("name", from), zap.Error(err))
+		return nil, err
+	}
+
+	movements := make([]snapshot.Movement, 0, toSnapshot.Total)
+	err = forEachStanding(ss.StandingsClient, b.ID, toSnapshot.ArchiveID(), func(entry standing.Standing) {
+		movement := snapshot.Movement{PlayerID: entry.PlayerID, PlayerName: entry.PlayerName, ToRank: &entry.Rank, ToScore: &entry.Score, Status: snapshot.MovementNew}
+		if before, ok := earlier[entry.PlayerID]; ok {
+			delete(earlier, entry.PlayerID)
+			movement.FromRank, movement.FromScore = &before.Rank, &before.Score
+			movement.RankChange = before.Rank - entry.Rank
+			switch {
+			case movement.RankChange > 0:
+				movement.Status = snapshot.MovementUp
+			case movement.RankChange < 0:
+				movement.Status = snapshot.MovementDown
+			default:
+				movement.Status = snapshot.MovementSame
+			}
+		}
+		movements = append(movements, movement)
+	})
+	if err != nil {
+		ss.Logger.Error("Error retrieving snapshot standings from DB", zap.String("board_id", b.ID), zap.String("name", to), zap.Error(err))
+		return nil, err
+	}
+
+	for _, playerID := range order {
+		if before, ok := earlier[playerID]; ok {
+			movements = append(movements, snapshot.Movement{PlayerID: playerID, PlayerName: before.PlayerName, FromRank: &before.Rank, FromScore: &before.Score, Status: snapshot.MovementDropped})
+		}
+	}
+	return movements, nil
+}
+
+// completeSnapshot retrieves a snapshot of the board, it returns ErrSnapshotIncomplete while the snapshot is being taken.
+func (ss *SnapshotService) completeSnapshot(boardID, name string) (snapshot.Snapshot, error) {
+	s, err := ss.SnapshotClient.GetSnapshot(boardID, name)
+	if err != nil {
+		return snapshot.Snapshot{}, err
+	}
+	if !s.Complete {
+		return snapshot.Snapshot{}, ErrSnapshotIncomplete
+	}
+	return s, nil
+}
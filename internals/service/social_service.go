@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/social"
+	"quiz/internals/repositories"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidFollow is returned when a player tries to follow themselves or a player ID is empty or too long.
+var ErrInvalidFollow = errors.New("players cannot follow themselves and player ids must be 1-128 characters")
+
+type SocialService struct {
+	SocialClient repositories.ISocialRepository // Interface for social graph storage operations
+	Scores       *PlayerScoreService            // Service ranking players against each other
+	CTX          context.Context                // Context for managing request-scoped values
+	Logger       *zap.Logger                    // Logger for structured logging
+}
+
+// NewSocialService initializes a new SocialService with the provided social graph repository, player score service, context, and logger.
+func NewSocialService(social_client repositories.ISocialRepository, scores *PlayerScoreService, ctx context.Context, custom_logger *zap.Logger) *SocialService {
+	return &SocialService{
+		SocialClient: social_client,
+		Scores:       scores,
+		CTX:          ctx,
+		Logger:       custom_logger,
+	}
+}
+
+// Follow makes the follower follow the followee. Players following each other are friends.
+func (ss *SocialService) Follow(followerID, followeeID string) error {
+	ss.Logger.Info("Follow method called", zap.String("follower_id", followerID), zap.String("followee_id", followeeID))
+
+	if !validFollow(followerID, followeeID) {
+		return ErrInvalidFollow
+	}
+
+	f := social.Follow{FollowerID: followerID, FolloweeID: followeeID, CreatedAt: time.Now().UTC()}
+	if err := ss.SocialClient.Follow(f); err != nil {
+		ss.Logger.Error("Error storing follow edge", zap.String("follower_id", followerID), zap.String("followee_id", followeeID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Unfollow removes the follow edge from the follower to the followee.
+func (ss *SocialService) Unfollow(followerID, followeeID string) error {
+	ss.Logger.Info("Unfollow method called", zap.String("follower_id", followerID), zap.String("followee_id", followeeID))
+
+	if !validFollow(followerID, followeeID) {
+		return ErrInvalidFollow
+	}
+
+	if err := ss.SocialClient.Unfollow(followerID, followeeID); err != nil {
+		ss.Logger.Error("Error removing follow edge", zap.String("follower_id", followerID), zap.String("followee_id", followeeID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetFollowing returns the IDs of the players the player follows.
+func (ss *SocialService) GetFollowing(playerID string) ([]string, error) {
+	return ss.SocialClient.GetFollowing(playerID)
+}
+
+// GetFollowers returns the IDs of the players following the player.
+func (ss *SocialService) GetFollowers(playerID string) ([]string, error) {
+	return ss.SocialClient.GetFollowers(playerID)
+}
+
+// GetFriends returns the IDs of the player's friends, the players they follow who follow them back.
+func (ss *SocialService) GetFriends(playerID string) ([]string, error) {
+	following, err := ss.SocialClient.GetFollowing(playerID)
+	if err != nil {
+		return nil, err
+	}
+	followers, err := ss.SocialClient.GetFollowers(playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	followsBack := make(map[string]bool, len(followers))
+	for _, followerID := range followers {
+		followsBack[followerID] = true
+	}
+
+	friends := []string{}
+	for _, followeeID := range following {
+		if followsBack[followeeID] {
+			friends = append(friends, followeeID)
+		}
+	}
+	return friends, nil
+}
+
+// GetFriendsLeaderboard ranks the player together with their friends, or with every player they follow, on the board.
+// Ranks are positions among these players only. Players without a score on the board are left out.
+func (ss *SocialService) GetFriendsLeaderboard(b board.Board, playerID string, relation social.Relation) ([]player_score.RankedPlayerScore, error) {
+	ss.Logger.Info("GetFriendsLeaderboard method called", zap.String("board_id", b.ID), zap.String("player_id", playerID), zap.String("relation", string(relation)))
+
+	var related []string
+	var err error
+	switch relation.OrDefault() {
+	case social.RelationFriends:
+		related, err = ss.GetFriends(playerID)
+	case social.RelationFollowing:
+		related, err = ss.GetFollowing(playerID)
+	default:
+		return nil, social.ErrInvalidRelation
+	}
+	if err != nil {
+		ss.Logger.Error("Error retrieving social graph", zap.String("player_id", playerID), zap.Error(err))
+		return nil, err
+	}
+
+	return ss.Scores.RankPlayers(b, append(related, playerID))
+}
+
+// validFollow reports whether a follow edge between the two players may exist.
+func validFollow(followerID, followeeID string) bool {
+	return followerID != "" && followeeID != "" && followerID != followeeID &&
+		len(followerID) <= maxPlayerIDLength && len(followeeID) <= maxPlayerIDLength
+}
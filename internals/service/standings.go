@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/standing"
+	"quiz/internals/repositories"
+)
+
+// archiveBatchSize is the number of ranked players read and archived per batch when a leaderboard is archived.
+const archiveBatchSize = 1000
+
+// archiveLeaderboard copies the whole ranked leaderboard of the board, page by page from the database, into the archive.
+// The database is read instead of the cache, so the archive keeps every field of the stored scores and they can be
+// matched against the scores stored later. Entries already archived under the ID are replaced, so an interrupted
+// archival can be repeated. It stops once ctx is cancelled and returns the number of archived players.
+func archiveLeaderboard(ctx context.Context, scores *PlayerScoreService, standings repositories.IStandingsRepository, b board.Board, archiveID string) (int64, error) {
+	if err := standings.DeleteStandings(b.ID, archiveID); err != nil {
+		return 0, err
+	}
+
+	tb := b.TieBreak.OrDefault()
+	var position int64
+	page := player_score.PageRequest{Limit: archiveBatchSize}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		players, err := scores.DBClient.GetTopPlayers(b.ID, tb, page)
+		if err != nil {
+			return 0, err
+		}
+		ranked, err := scores.dbRanks(b.ID, tb).rankPage(tb, players, position+1)
+		if err != nil {
+			return 0, err
+		}
+		scores.JoinProfiles(ranked)
+
+		entries := make([]standing.Standing, len(ranked))
+		for i, player := range ranked {
+			position++
+			entries[i] = standing.Standing{BoardID: b.ID, ArchiveID: archiveID, Position: position, RankedPlayerScore: player}
+		}
+		if err := standings.SaveStandings(entries); err != nil {
+			return 0, err
+		}
+
+		if int64(len(players)) < archiveBatchSize {
+			return position, nil
+		}
+		cursor := player_score.CursorOf(players[len(players)-1])
+		page.After = &cursor
+	}
+}
+
+// resetArchivedScores removes the scores of the board that are still stored as they were archived, batch by batch
+// through the archive, and drops the cached leaderboards of the board. Scores written after they were archived are
+// kept, so they carry over instead of being lost.
+func resetArchivedScores(scores *PlayerScoreService, standings repositories.IStandingsRepository, b board.Board, archiveID string) error {
+	var position int64
+	for {
+		entries, err := standings.GetStandings(b.ID, archiveID, position, archiveBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			break
+		}
+
+		players := make([]player_score.PlayerScore, len(entries))
+		for i, entry := range entries {
+			players[i] = entry.PlayerScore
+		}
+		if err := scores.DBClient.DeleteArchivedScores(b.ID, players); err != nil {
+			return err
+		}
+
+		if position = entries[len(entries)-1].Position; int64(len(entries)) < archiveBatchSize {
+			break
+		}
+	}
+	return scores.dropLeaderboards(b)
+}
+
+// readArchive returns a page of an archive holding total players, with the ranks they had when it was taken.
+// Pages continuing after a cursor start behind the cursor's player in the archive.
+func readArchive(standings repositories.IStandingsRepository, boardID, archiveID string, total int64, page player_score.PageRequest) (player_score.Page, error) {
+	after := page.Offset
+	if page.After != nil {
+		position, err := standings.GetStandingPosition(boardID, archiveID, page.After.PlayerID)
+		if err != nil {
+			return player_score.Page{}, player_score.ErrInvalidPageToken
+		}
+		after = position
+	}
+
+	// Fetch one extra entry to find out whether there is a next page
+	limit := page.Limit
+	if limit > 0 {
+		limit++
+	}
+
+	entries, err := standings.GetStandings(boardID, archiveID, after, limit)
+	if err != nil {
+		return player_score.Page{}, err
+	}
+
+	ranked := make([]player_score.RankedPlayerScore, len(entries))
+	for i, entry := range entries {
+		ranked[i] = entry.RankedPlayerScore
+	}
+	return newPage(ranked, total, page.Limit), nil
+}
+
+// forEachStanding calls fn with every entry of an archive in position order, reading archiveBatchSize entries at a time.
+func forEachStanding(standings repositories.IStandingsRepository, boardID, archiveID string, fn func(standing.Standing)) error {
+	var after int64
+	for {
+		entries, err := standings.GetStandings(boardID, archiveID, after, archiveBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			fn(entry)
+		}
+		if int64(len(entries)) < archiveBatchSize {
+			return nil
+		}
+		after = entries[len(entries)-1].Position
+	}
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/domain/board"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BoardsHandler struct {
+	Service *service.BoardService // Service to handle board operations
+}
+
+// NewBoardsHandler initializes a new BoardsHandler with the provided service.
+func NewBoardsHandler(service *service.BoardService) *BoardsHandler {
+	return &BoardsHandler{Service: service}
+}
+
+// createBoardRequest is the body accepted by CreateBoardHandler.
+type createBoardRequest struct {
+	ID           string                    `json:"id" binding:"required"`
+	Name         string                    `json:"name"`
+	TieBreak     player_score.TieBreak     `json:"tie_break"`
+	UpdatePolicy player_score.UpdatePolicy `json:"update_policy"`
+}
+
+// CreateBoardHandler handles requests to create a new board.
+func (bh *BoardsHandler) CreateBoardHandler(c *gin.Context) {
+	var req createBoardRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	b, err := bh.Service.CreateBoard(req.ID, req.Name, req.TieBreak, req.UpdatePolicy)
+	switch {
+	case errors.Is(err, service.ErrInvalidBoardID), errors.Is(err, service.ErrInvalidTieBreak), errors.Is(err, service.ErrInvalidUpdatePolicy):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrBoardExists):
+		c.JSON(409, gin.H{"error": "Board already exists"})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to create board"})
+		return
+	}
+
+	c.JSON(201, gin.H{"board": b})
+}
+
+// ListBoardsHandler returns every board.
+func (bh *BoardsHandler) ListBoardsHandler(c *gin.Context) {
+	boards, err := bh.Service.GetBoards()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve boards"})
+		return
+	}
+
+	c.JSON(200, gin.H{"boards": boards})
+}
+
+// GetBoardHandler returns a single board by its ID.
+func (bh *BoardsHandler) GetBoardHandler(c *gin.Context) {
+	b, err := bh.Service.GetBoard(c.Param("board"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Board not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"board": b})
+}
+
+// DeleteBoardHandler deletes a board together with all of its scores and seasons.
+func (bh *BoardsHandler) DeleteBoardHandler(c *gin.Context) {
+	err := bh.Service.DeleteBoard(c.Param("board"))
+	if errors.Is(err, repositories.ErrBoardNotFound) {
+		c.JSON(404, gin.H{"error": "Board not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to delete board"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Board deleted"})
+}
+
+// boardContextKey is the gin context key under which RequireBoard stores the board of the route.
+const boardContextKey = "board"
+
+// RequireBoard is a middleware that aborts with 404 when the board named in the route does not exist.
+// Otherwise it stores the board in the context, where handlers read it with currentBoard.
+func (bh *BoardsHandler) RequireBoard(c *gin.Context) {
+	b, err := bh.Service.GetBoard(c.Param("board"))
+	if err != nil {
+		c.AbortWithStatusJSON(404, gin.H{"error": "Board not found"})
+		return
+	}
+	c.Set(boardContextKey, b)
+	c.Next()
+}
+
+// currentBoard returns the board loaded by RequireBoard.
+func currentBoard(c *gin.Context) board.Board {
+	return c.MustGet(boardContextKey).(board.Board)
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsistencyHandler struct {
+	Service *service.ConsistencyService // Service comparing the cached leaderboards with the database
+}
+
+// NewConsistencyHandler initializes a new ConsistencyHandler with the provided service.
+func NewConsistencyHandler(service *service.ConsistencyService) *ConsistencyHandler {
+	return &ConsistencyHandler{Service: service}
+}
+
+// CheckHandler reports the drift between the cached leaderboard of the board and the database without changing anything.
+func (ch *ConsistencyHandler) CheckHandler(c *gin.Context) {
+	report, err := ch.Service.CheckBoard(currentBoard(c), false)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to check the cached leaderboard"})
+		return
+	}
+
+	c.JSON(200, report)
+}
+
+// RepairHandler reports the drift between the cached leaderboard of the board and the database and repairs it.
+func (ch *ConsistencyHandler) RepairHandler(c *gin.Context) {
+	report, err := ch.Service.CheckBoard(currentBoard(c), true)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to repair the cached leaderboard"})
+		return
+	}
+
+	c.JSON(200, report)
+}
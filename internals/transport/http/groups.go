@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/domain/group"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GroupsHandler struct {
+	Service *service.GroupService // Service to handle group operations
+}
+
+// NewGroupsHandler initializes a new GroupsHandler with the provided service.
+func NewGroupsHandler(service *service.GroupService) *GroupsHandler {
+	return &GroupsHandler{Service: service}
+}
+
+// createGroupRequest is the body accepted by CreateGroupHandler.
+type createGroupRequest struct {
+	ID          string            `json:"id" binding:"required"`
+	Name        string            `json:"name"`
+	Aggregation group.Aggregation `json:"aggregation"`
+	K           int               `json:"k"`
+}
+
+// CreateGroupHandler handles requests to create a new group.
+func (gh *GroupsHandler) CreateGroupHandler(c *gin.Context) {
+	var req createGroupRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	g, err := gh.Service.CreateGroup(req.ID, req.Name, req.Aggregation, req.K)
+	switch {
+	case errors.Is(err, service.ErrInvalidGroupID), errors.Is(err, service.ErrInvalidAggregation):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrGroupExists):
+		c.JSON(409, gin.H{"error": "Group already exists"})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to create group"})
+		return
+	}
+
+	c.JSON(201, gin.H{"group": g})
+}
+
+// ListGroupsHandler returns every group.
+func (gh *GroupsHandler) ListGroupsHandler(c *gin.Context) {
+	groups, err := gh.Service.GetGroups()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve groups"})
+		return
+	}
+
+	c.JSON(200, gin.H{"groups": groups})
+}
+
+// GetGroupHandler returns a single group by its ID.
+func (gh *GroupsHandler) GetGroupHandler(c *gin.Context) {
+	g, err := gh.Service.GetGroup(c.Param("group"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Group not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"group": g})
+}
+
+// DeleteGroupHandler deletes a group together with its memberships.
+func (gh *GroupsHandler) DeleteGroupHandler(c *gin.Context) {
+	if err := gh.Service.DeleteGroup(c.Param("group")); err != nil {
+		groupError(c, err, "Failed to delete group")
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Group deleted"})
+}
+
+// ListMembersHandler returns the IDs of the members of a group.
+func (gh *GroupsHandler) ListMembersHandler(c *gin.Context) {
+	members, err := gh.Service.GetMembers(c.Param("group"))
+	if err != nil {
+		groupError(c, err, "Failed to retrieve group members")
+		return
+	}
+
+	c.JSON(200, gin.H{"group_id": c.Param("group"), "members": members})
+}
+
+// AddMemberHandler adds the player named in the route to a group.
+func (gh *GroupsHandler) AddMemberHandler(c *gin.Context) {
+	if err := gh.Service.AddMember(c.Param("group"), c.Param("id")); err != nil {
+		groupError(c, err, "Failed to add group member")
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Player added to group"})
+}
+
+// RemoveMemberHandler removes the player named in the route from a group.
+func (gh *GroupsHandler) RemoveMemberHandler(c *gin.Context) {
+	if err := gh.Service.RemoveMember(c.Param("group"), c.Param("id")); err != nil {
+		groupError(c, err, "Failed to remove group member")
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Player removed from group"})
+}
+
+// TopGroupsHandler returns a page of the groups ranked by the scores of their members on the board named in the route.
+// Pages are selected with the limit and offset query parameters.
+func (gh *GroupsHandler) TopGroupsHandler(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err == nil && page.After != nil {
+		err = errors.New("page_token is not supported by group leaderboards, use offset")
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	standings, total, err := gh.Service.GetGroupLeaderboard(currentBoard(c), page.Offset, page.Limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve top groups"})
+		return
+	}
+
+	c.JSON(200, gin.H{"top_groups": standings, "total": total})
+}
+
+// groupError responds to a failed group operation with the status matching the error.
+func groupError(c *gin.Context, err error, message string) {
+	if errors.Is(err, repositories.ErrGroupNotFound) {
+		c.JSON(404, gin.H{"error": "Group not found"})
+		return
+	}
+	c.JSON(500, gin.H{"error": message})
+}
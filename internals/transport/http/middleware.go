@@ -0,0 +1,34 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	requestIDHeader     = "X-Request-ID" // Header carrying the request ID in both directions
+	requestIDContextKey = "request_id"   // gin context key under which RequestID stores the request ID
+)
+
+// RequestID is a middleware that makes sure every request has an ID. It keeps the ID sent by the client in
+// the X-Request-ID header, or generates a new one, and echoes it back in the response header.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(requestIDHeader)
+	if id == "" {
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err == nil {
+			id = hex.EncodeToString(raw)
+		}
+	}
+
+	c.Set(requestIDContextKey, id)
+	c.Header(requestIDHeader, id)
+	c.Next()
+}
+
+// requestID returns the ID assigned to the request by RequestID.
+func requestID(c *gin.Context) string {
+	return c.GetString(requestIDContextKey)
+}
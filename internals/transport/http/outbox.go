@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxHandler struct {
+	Service *service.OutboxRelay // Relay applying the outbox to the cache
+}
+
+// NewOutboxHandler initializes a new OutboxHandler with the provided relay.
+func NewOutboxHandler(service *service.OutboxRelay) *OutboxHandler {
+	return &OutboxHandler{Service: service}
+}
+
+// DeadEntriesHandler returns a page of the outbox entries the relay gave up on, selected with limit and offset.
+func (oh *OutboxHandler) DeadEntriesHandler(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err == nil && page.After != nil {
+		err = errors.New("page_token is not supported by the outbox, use offset")
+	}
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := oh.Service.GetDeadEntries(page.Offset, page.Limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve dead outbox entries"})
+		return
+	}
+
+	c.JSON(200, gin.H{"entries": entries})
+}
+
+// RetryDeadEntryHandler moves a dead outbox entry back to the relay's queue.
+func (oh *OutboxHandler) RetryDeadEntryHandler(c *gin.Context) {
+	err := oh.Service.RetryDeadEntry(c.Param("id"))
+	if errors.Is(err, repositories.ErrOutboxEntryNotFound) {
+		c.JSON(404, gin.H{"error": "Dead outbox entry not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retry outbox entry"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Outbox entry queued for retry"})
+}
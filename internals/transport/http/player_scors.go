@@ -1,12 +1,29 @@
 package http
 
 import (
+	"errors"
+	"quiz/internals/domain/board"
 	"quiz/internals/domain/player_score"
+	"quiz/internals/domain/profile"
+	"quiz/internals/domain/score_event"
+	"quiz/internals/domain/window"
 	"quiz/internals/service"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// errScopeConflict is returned when a request selects more than one of the window, country and region leaderboards.
+var errScopeConflict = errors.New("only one of window, country and region may be given")
+
+const (
+	defaultPageSize = 100  // Number of entries returned when no limit is given
+	maxPageSize     = 1000 // Largest limit a client may ask for
+	defaultRadius   = 5    // Number of players shown on each side of a player when no radius is given
+	maxRadius       = 100  // Largest radius a client may ask for
+)
+
 type PlayerScoresHandler struct {
 	Service *service.PlayerScoreService // Service to handle player score operations
 }
@@ -16,7 +33,7 @@ func NewPlayerScoreHandler(service *service.PlayerScoreService) *PlayerScoresHan
 	return &PlayerScoresHandler{Service: service}
 }
 
-// AddOrUpdateHandler handles requests to add or update a player's score.
+// AddOrUpdateHandler handles requests to add or update a player's score on the board named in the route.
 func (psh *PlayerScoresHandler) AddOrUpdateHandler(c *gin.Context) {
 	var req player_score.PlayerScore
 	if err := c.BindJSON(&req); err != nil {
@@ -25,36 +42,225 @@ func (psh *PlayerScoresHandler) AddOrUpdateHandler(c *gin.Context) {
 	}
 
 	// Update or insert the player score via the service
-	if err := psh.Service.AddOrUpdatePlayerScore(req); err != nil {
+	origin := score_event.Origin{Source: "add_or_update", RequestID: requestID(c)}
+	change, err := psh.Service.AddOrUpdatePlayerScore(currentBoard(c), req, origin)
+	if errors.Is(err, service.ErrCompletionTimeRequired) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to update player score"})
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Player score added or updated"})
+	message := "Player score added or updated"
+	if !change.Replaced {
+		message = "Player score kept by the board's update policy"
+	}
+	c.JSON(200, gin.H{"message": message, "old_score": change.OldScore, "new_score": change.NewScore, "created": change.Created, "replaced": change.Replaced})
+}
+
+// IncrementHandler handles requests to atomically add a delta to a player's score on the board named in the route.
+// A negative delta decrements the score, the optional floor and ceiling limit the resulting score.
+func (psh *PlayerScoresHandler) IncrementHandler(c *gin.Context) {
+	var req player_score.Increment
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	// Increment the player score via the service
+	origin := score_event.Origin{Source: "increment", RequestID: requestID(c)}
+	change, err := psh.Service.IncrementPlayerScore(currentBoard(c), req, origin)
+	if errors.Is(err, service.ErrInvalidIncrement) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to increment player score"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Player score incremented", "old_score": change.OldScore, "new_score": change.NewScore, "created": change.Created})
 }
 
-// TopPlayersHandler retrieves and returns the top players.
+// TopPlayersHandler retrieves and returns a page of the top players of the board named in the route.
+// Pages are selected with the limit and offset query parameters, or with page_token to continue after a previous page.
+// The optional window query parameter selects the leaderboard of the current day, week or month, and the optional
+// country or region query parameter selects the leaderboard of the players from one country or region.
 func (psh *PlayerScoresHandler) TopPlayersHandler(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := psh.scopeBoard(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Fetch the top players via the service
-	topPlayers, err := psh.Service.GetTopPlayers(c.Request.Context())
+	topPlayers, err := psh.Service.GetTopPlayers(c.Request.Context(), b, page)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Failed to retrieve top players"})
 		return
 	}
 
-	c.JSON(200, gin.H{"top_players": topPlayers})
+	c.JSON(200, topPlayers)
 }
 
-// GetPointsHandler fetches and returns the score for a specific player by their ID.
+// GetPointsHandler fetches and returns the score for a specific player on the board by their ID,
+// together with whether it was served from cache.
 func (psh *PlayerScoresHandler) GetPointsHandler(c *gin.Context) {
 	playerID := c.Param("id")
 
 	// Get the player score via the service
-	playerScore, err := psh.Service.GetPlayerScore(playerID)
+	playerScore, cached, err := psh.Service.GetPlayerScore(currentBoard(c), playerID)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Player not found", "cached": cached})
+		return
+	}
+
+	c.JSON(200, gin.H{"player_id": playerID, "score": playerScore, "cached": cached})
+}
+
+// GetRankHandler returns the rank, score and percentile of a specific player on the board by their ID.
+// The optional window, country or region query parameter ranks the player on the matching leaderboard instead.
+func (psh *PlayerScoresHandler) GetRankHandler(c *gin.Context) {
+	b, err := psh.scopeBoard(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	rank, err := psh.Service.GetPlayerRank(b, c.Param("id"))
 	if err != nil {
 		c.JSON(404, gin.H{"error": "Player not found"})
 		return
 	}
 
-	c.JSON(200, gin.H{"player_id": playerID, "score": playerScore})
+	c.JSON(200, rank)
+}
+
+// AroundHandler returns the players ranked around a specific player on the board, radius players above and below.
+// The optional window, country or region query parameter reads the matching leaderboard instead.
+func (psh *PlayerScoresHandler) AroundHandler(c *gin.Context) {
+	radius := int64(defaultRadius)
+	if value := c.Query("radius"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 || parsed > maxRadius {
+			c.JSON(400, gin.H{"error": "radius must be a number between 0 and " + strconv.Itoa(maxRadius)})
+			return
+		}
+		radius = parsed
+	}
+
+	b, err := psh.scopeBoard(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	rank, players, err := psh.Service.GetPlayersAround(b, c.Param("id"), radius)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Player not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"player": rank, "players": players})
+}
+
+// HistoryHandler returns the recorded score changes of a specific player on the board, newest first.
+// The optional from and to query parameters (RFC 3339) restrict the time range and limit caps the number of events.
+func (psh *PlayerScoresHandler) HistoryHandler(c *gin.Context) {
+	var from, to time.Time
+	for name, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": name + " must be an RFC 3339 timestamp"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	limit := int64(defaultPageSize)
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 1 || parsed > maxPageSize {
+			c.JSON(400, gin.H{"error": "limit must be a number between 1 and " + strconv.Itoa(maxPageSize)})
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := psh.Service.GetScoreHistory(currentBoard(c), c.Param("id"), from, to, limit)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve score history"})
+		return
+	}
+
+	c.JSON(200, gin.H{"player_id": c.Param("id"), "events": events})
+}
+
+// scopeBoard returns the board named in the route, or the board of one of its scopes: the current period of a
+// scheduled window when the window query parameter is set, or the players of one country or region when the
+// country or region query parameter is set. At most one of them may be given.
+func (psh *PlayerScoresHandler) scopeBoard(c *gin.Context) (board.Board, error) {
+	var filters []profile.Attribute
+	for _, a := range profile.Attributes {
+		if c.Query(string(a)) != "" {
+			filters = append(filters, a)
+		}
+	}
+
+	w := window.Window(c.Query("window"))
+	if len(filters) > 1 || (len(filters) == 1 && w != "") {
+		return board.Board{}, errScopeConflict
+	}
+	if len(filters) == 1 {
+		return psh.Service.AttributeBoard(currentBoard(c), filters[0], c.Query(string(filters[0])))
+	}
+
+	if w == "" {
+		w = window.AllTime
+	}
+	if !w.Valid() {
+		return board.Board{}, window.ErrInvalidWindow
+	}
+	return psh.Service.WindowBoard(currentBoard(c), w)
+}
+
+// parsePageRequest reads the limit, offset and page_token query parameters of a leaderboard request.
+func parsePageRequest(c *gin.Context) (player_score.PageRequest, error) {
+	page := player_score.PageRequest{Limit: defaultPageSize}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return page, errors.New("limit must be a number between 1 and " + strconv.Itoa(maxPageSize))
+		}
+		page.Limit = limit
+	}
+
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || offset < 0 {
+			return page, errors.New("offset must be a non-negative number")
+		}
+		page.Offset = offset
+	}
+
+	if token := c.Query("page_token"); token != "" {
+		cursor, err := player_score.DecodeCursor(token)
+		if err != nil {
+			return page, err
+		}
+		page.After = &cursor
+	}
+
+	return page, nil
 }
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/domain/profile"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfilesHandler struct {
+	Service *service.ProfileService // Service to handle player profile operations
+}
+
+// NewProfilesHandler initializes a new ProfilesHandler with the provided service.
+func NewProfilesHandler(service *service.ProfileService) *ProfilesHandler {
+	return &ProfilesHandler{Service: service}
+}
+
+// CreateProfileHandler handles requests to create the profile of a player.
+func (ph *ProfilesHandler) CreateProfileHandler(c *gin.Context) {
+	var req profile.Profile
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	p, err := ph.Service.CreateProfile(req)
+	switch {
+	case isProfileValidationError(err):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrProfileExists):
+		c.JSON(409, gin.H{"error": "Profile already exists"})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to create profile"})
+		return
+	}
+
+	c.JSON(201, gin.H{"profile": p})
+}
+
+// GetProfileHandler returns the profile of the player named in the route.
+func (ph *ProfilesHandler) GetProfileHandler(c *gin.Context) {
+	p, err := ph.Service.GetProfile(c.Param("id"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"profile": p})
+}
+
+// UpdateProfileHandler changes the fields given in the body on the profile of the player named in the route.
+func (ph *ProfilesHandler) UpdateProfileHandler(c *gin.Context) {
+	var req profile.Update
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	p, err := ph.Service.UpdateProfile(c.Param("id"), req)
+	switch {
+	case isProfileValidationError(err):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrProfileNotFound):
+		c.JSON(404, gin.H{"error": "Profile not found"})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	c.JSON(200, gin.H{"profile": p})
+}
+
+// DeleteProfileHandler deletes the profile of the player named in the route, their scores are kept.
+func (ph *ProfilesHandler) DeleteProfileHandler(c *gin.Context) {
+	err := ph.Service.DeleteProfile(c.Param("id"))
+	if errors.Is(err, repositories.ErrProfileNotFound) {
+		c.JSON(404, gin.H{"error": "Profile not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to delete profile"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Profile deleted"})
+}
+
+// isProfileValidationError reports whether the error was caused by invalid profile fields.
+func isProfileValidationError(err error) bool {
+	return errors.Is(err, service.ErrInvalidPlayerID) || errors.Is(err, service.ErrInvalidDisplayName) ||
+		errors.Is(err, service.ErrInvalidAvatarURL) || errors.Is(err, service.ErrInvalidCountry) ||
+		errors.Is(err, service.ErrInvalidRegion)
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeasonsHandler struct {
+	Service *service.SeasonService // Service to handle season operations
+}
+
+// NewSeasonsHandler initializes a new SeasonsHandler with the provided service.
+func NewSeasonsHandler(service *service.SeasonService) *SeasonsHandler {
+	return &SeasonsHandler{Service: service}
+}
+
+// startSeasonRequest is the body accepted by StartSeasonHandler.
+type startSeasonRequest struct {
+	ID   string `json:"id" binding:"required"`
+	Name string `json:"name"`
+}
+
+// StartSeasonHandler handles requests to start a new season on the board named in the route.
+func (sh *SeasonsHandler) StartSeasonHandler(c *gin.Context) {
+	var req startSeasonRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	s, err := sh.Service.StartSeason(currentBoard(c), req.ID, req.Name)
+	switch {
+	case errors.Is(err, service.ErrInvalidSeasonID):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrSeasonExists):
+		c.JSON(409, gin.H{"error": "Season already exists"})
+		return
+	case errors.Is(err, repositories.ErrSeasonActive):
+		c.JSON(409, gin.H{"error": "Another season is still active"})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to start season"})
+		return
+	}
+
+	c.JSON(201, gin.H{"season": s})
+}
+
+// ListSeasonsHandler returns every season of the board named in the route, newest first.
+func (sh *SeasonsHandler) ListSeasonsHandler(c *gin.Context) {
+	seasons, err := sh.Service.GetSeasons(currentBoard(c))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve seasons"})
+		return
+	}
+
+	c.JSON(200, gin.H{"seasons": seasons})
+}
+
+// GetSeasonHandler returns a single season of the board named in the route.
+func (sh *SeasonsHandler) GetSeasonHandler(c *gin.Context) {
+	s, err := sh.Service.GetSeason(currentBoard(c), c.Param("season"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Season not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"season": s})
+}
+
+// EndSeasonHandler ends the active season, archiving its final standings and resetting the live scores of the board.
+func (sh *SeasonsHandler) EndSeasonHandler(c *gin.Context) {
+	s, err := sh.Service.EndSeason(currentBoard(c), c.Param("season"))
+	switch {
+	case errors.Is(err, repositories.ErrSeasonNotFound):
+		c.JSON(404, gin.H{"error": "Season not found"})
+		return
+	case errors.Is(err, service.ErrSeasonNotActive):
+		c.JSON(409, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to end season"})
+		return
+	}
+
+	c.JSON(200, gin.H{"season": s})
+}
+
+// SeasonTopPlayersHandler returns a page of the final standings of a season, or of the live leaderboard while it is active.
+// Pages are selected the same way as in TopPlayersHandler.
+func (sh *SeasonsHandler) SeasonTopPlayersHandler(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	topPlayers, err := sh.Service.GetSeasonTopPlayers(c.Request.Context(), currentBoard(c), c.Param("season"), page)
+	switch {
+	case errors.Is(err, repositories.ErrSeasonNotFound):
+		c.JSON(404, gin.H{"error": "Season not found"})
+		return
+	case errors.Is(err, player_score.ErrInvalidPageToken):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to retrieve season standings"})
+		return
+	}
+
+	c.JSON(200, topPlayers)
+}
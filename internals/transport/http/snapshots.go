@@ -0,0 +1,134 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/domain/player_score"
+	"quiz/internals/repositories"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SnapshotsHandler struct {
+	Service *service.SnapshotService // Service to handle snapshot operations
+}
+
+// NewSnapshotsHandler initializes a new SnapshotsHandler with the provided service.
+func NewSnapshotsHandler(service *service.SnapshotService) *SnapshotsHandler {
+	return &SnapshotsHandler{Service: service}
+}
+
+// takeSnapshotRequest is the body accepted by TakeSnapshotHandler.
+type takeSnapshotRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// TakeSnapshotHandler handles requests to capture the current ranking of the board named in the route.
+func (sh *SnapshotsHandler) TakeSnapshotHandler(c *gin.Context) {
+	var req takeSnapshotRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	s, err := sh.Service.TakeSnapshot(c.Request.Context(), currentBoard(c), req.Name)
+	switch {
+	case errors.Is(err, service.ErrInvalidSnapshotName):
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repositories.ErrSnapshotExists):
+		c.JSON(409, gin.H{"error": "Snapshot already exists"})
+		return
+	case err != nil:
+		c.JSON(500, gin.H{"error": "Failed to take snapshot"})
+		return
+	}
+
+	c.JSON(201, gin.H{"snapshot": s})
+}
+
+// ListSnapshotsHandler returns every snapshot of the board named in the route, newest first.
+func (sh *SnapshotsHandler) ListSnapshotsHandler(c *gin.Context) {
+	snapshots, err := sh.Service.GetSnapshots(currentBoard(c))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve snapshots"})
+		return
+	}
+
+	c.JSON(200, gin.H{"snapshots": snapshots})
+}
+
+// GetSnapshotHandler returns a single snapshot of the board named in the route.
+func (sh *SnapshotsHandler) GetSnapshotHandler(c *gin.Context) {
+	s, err := sh.Service.GetSnapshot(currentBoard(c), c.Param("snapshot"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Snapshot not found"})
+		return
+	}
+
+	c.JSON(200, gin.H{"snapshot": s})
+}
+
+// DeleteSnapshotHandler deletes a snapshot together with its ranked players.
+func (sh *SnapshotsHandler) DeleteSnapshotHandler(c *gin.Context) {
+	err := sh.Service.DeleteSnapshot(currentBoard(c), c.Param("snapshot"))
+	if errors.Is(err, repositories.ErrSnapshotNotFound) {
+		c.JSON(404, gin.H{"error": "Snapshot not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to delete snapshot"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Snapshot deleted"})
+}
+
+// SnapshotTopPlayersHandler returns a page of the ranked players of a snapshot, paged the same way as TopPlayersHandler.
+func (sh *SnapshotsHandler) SnapshotTopPlayersHandler(c *gin.Context) {
+	page, err := parsePageRequest(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	topPlayers, err := sh.Service.GetSnapshotTopPlayers(currentBoard(c), c.Param("snapshot"), page)
+	if err != nil {
+		snapshotError(c, err, "Failed to retrieve snapshot standings")
+		return
+	}
+
+	c.JSON(200, topPlayers)
+}
+
+// DiffSnapshotsHandler returns the rank movement of every player between the earlier snapshot named
+// by the from query parameter and the snapshot named in the route.
+func (sh *SnapshotsHandler) DiffSnapshotsHandler(c *gin.Context) {
+	from := c.Query("from")
+	if from == "" {
+		c.JSON(400, gin.H{"error": "from must name the snapshot to compare with"})
+		return
+	}
+
+	movements, err := sh.Service.DiffSnapshots(currentBoard(c), from, c.Param("snapshot"))
+	if err != nil {
+		snapshotError(c, err, "Failed to compare snapshots")
+		return
+	}
+
+	c.JSON(200, gin.H{"from": from, "to": c.Param("snapshot"), "players": movements})
+}
+
+// snapshotError responds to a failed snapshot read with the status matching the error.
+func snapshotError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repositories.ErrSnapshotNotFound):
+		c.JSON(404, gin.H{"error": "Snapshot not found"})
+	case errors.Is(err, service.ErrSnapshotIncomplete):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, player_score.ErrInvalidPageToken):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"quiz/internals/domain/social"
+	"quiz/internals/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SocialHandler struct {
+	Service *service.SocialService // Service to handle social graph operations
+}
+
+// NewSocialHandler initializes a new SocialHandler with the provided service.
+func NewSocialHandler(service *service.SocialService) *SocialHandler {
+	return &SocialHandler{Service: service}
+}
+
+// FollowHandler makes the player named in the route follow the other player named in the route.
+func (sh *SocialHandler) FollowHandler(c *gin.Context) {
+	err := sh.Service.Follow(c.Param("id"), c.Param("other"))
+	if errors.Is(err, service.ErrInvalidFollow) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to follow player"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Player followed"})
+}
+
+// UnfollowHandler makes the player named in the route stop following the other player named in the route.
+func (sh *SocialHandler) UnfollowHandler(c *gin.Context) {
+	err := sh.Service.Unfollow(c.Param("id"), c.Param("other"))
+	if errors.Is(err, service.ErrInvalidFollow) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to unfollow player"})
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "Player unfollowed"})
+}
+
+// FollowingHandler returns the IDs of the players the player named in the route follows.
+func (sh *SocialHandler) FollowingHandler(c *gin.Context) {
+	following, err := sh.Service.GetFollowing(c.Param("id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve followed players"})
+		return
+	}
+
+	c.JSON(200, gin.H{"player_id": c.Param("id"), "following": following})
+}
+
+// FollowersHandler returns the IDs of the players following the player named in the route.
+func (sh *SocialHandler) FollowersHandler(c *gin.Context) {
+	followers, err := sh.Service.GetFollowers(c.Param("id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve followers"})
+		return
+	}
+
+	c.JSON(200, gin.H{"player_id": c.Param("id"), "followers": followers})
+}
+
+// FriendsHandler returns the IDs of the friends of the player named in the route.
+func (sh *SocialHandler) FriendsHandler(c *gin.Context) {
+	friends, err := sh.Service.GetFriends(c.Param("id"))
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve friends"})
+		return
+	}
+
+	c.JSON(200, gin.H{"player_id": c.Param("id"), "friends": friends})
+}
+
+// FriendsLeaderboardHandler ranks the player named in the route among their friends on the board named in the route.
+// The optional relation query parameter set to "following" ranks them among every player they follow instead.
+func (sh *SocialHandler) FriendsLeaderboardHandler(c *gin.Context) {
+	relation := social.Relation(c.Query("relation")).OrDefault()
+	if !relation.Valid() {
+		c.JSON(400, gin.H{"error": social.ErrInvalidRelation.Error()})
+		return
+	}
+
+	players, err := sh.Service.GetFriendsLeaderboard(currentBoard(c), c.Param("id"), relation)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to retrieve friends leaderboard"})
+		return
+	}
+
+	c.JSON(200, gin.H{"player_id": c.Param("id"), "relation": relation, "players": players})
+}